@@ -33,11 +33,28 @@ type Config struct {
 	*PGSQLConfig
 	*RMQConfig
 	*BlobStorage
+	*WSConfig
+	*HttpClientConfig
 }
 
 type HttpConfig struct {
-	PORT   string      `json:"port"`
-	Logger *log.Logger `json:"-"`
+	PORT                   string      `json:"port"`
+	ADMIN_PORT             string      `json:"admin_port"`
+	ADMIN_HOST             string      `json:"admin_host"`
+	ERROR_MODE             string      `json:"error_mode"`
+	SEC_HEADERS_ENABLED    bool        `json:"sec_headers_enabled"`
+	SEC_HSTS_MAX_AGE       int         `json:"sec_hsts_max_age"`
+	SEC_CSP                string      `json:"sec_csp"`
+	SEC_FRAME_OPTIONS      string      `json:"sec_frame_options"`
+	SEC_REFERRER_POLICY    string      `json:"sec_referrer_policy"`
+	SEC_PERMISSIONS_POLICY string      `json:"sec_permissions_policy"`
+	CORS_ORIGINS           []string    `json:"cors_origins"`
+	CORS_METHODS           []string    `json:"cors_methods"`
+	CORS_HEADERS           []string    `json:"cors_headers"`
+	CORS_CREDENTIALS       bool        `json:"cors_credentials"`
+	CORS_MAX_AGE           int         `json:"cors_max_age"`
+	OPENAPI_PATH           string      `json:"openapi_path"`
+	Logger                 *log.Logger `json:"-"`
 }
 
 type MongoDBConfig struct {
@@ -47,13 +64,19 @@ type MongoDBConfig struct {
 }
 
 type RedisDBConfig struct {
-	RDB_HOST       string `json:"rdb_host"`
-	RDB_PORT       string `json:"rdb_port"`
-	RDB_USER       string `json:"rdb_user"`
-	RDB_PASS       string `json:"rdb_pass"`
-	RDB_DB         int64  `json:"rdb_db"`
-	RDB_DSN        string `json:"-"`
-	PUBSUB_CHANNEL string `json:"-"`
+	RDB_HOST           string `json:"rdb_host"`
+	RDB_PORT           string `json:"rdb_port"`
+	RDB_USER           string `json:"rdb_user"`
+	RDB_PASS           string `json:"rdb_pass"`
+	RDB_DB             int64  `json:"rdb_db"`
+	RDB_DSN            string `json:"-"`
+	PUBSUB_CHANNEL     string `json:"-"`
+	RDB_POOL_SIZE      int    `json:"rdb_pool_size"`
+	RDB_MIN_IDLE_CONNS int    `json:"rdb_min_idle_conns"`
+	RDB_DIAL_TIMEOUT   int    `json:"rdb_dial_timeout"`
+	RDB_READ_TIMEOUT   int    `json:"rdb_read_timeout"`
+	RDB_WRITE_TIMEOUT  int    `json:"rdb_write_timeout"`
+	RDB_POOL_TIMEOUT   int    `json:"rdb_pool_timeout"`
 }
 
 type PGSQLConfig struct {
@@ -83,6 +106,24 @@ type BlobStorage struct {
 	BS_URL_EXPIRY_TIME int64  `json:"bs_url_expiry_time"`
 }
 
+type WSConfig struct {
+	WS_ALLOWED_ORIGINS  []string `json:"ws_allowed_origins"`
+	WS_SEND_BUFFER_SIZE int      `json:"ws_send_buffer_size"`
+	WS_PING_INTERVAL    int      `json:"ws_ping_interval"`
+	WS_MAX_MESSAGE_SIZE int64    `json:"ws_max_message_size"`
+}
+
+type HttpClientConfig struct {
+	HC_BASE_URL                string `json:"hc_base_url"`
+	HC_TIMEOUT                 int    `json:"hc_timeout"`
+	HC_MAX_IDLE_CONNS          int    `json:"hc_max_idle_conns"`
+	HC_MAX_IDLE_CONNS_PER_HOST int    `json:"hc_max_idle_conns_per_host"`
+	HC_IDLE_CONN_TIMEOUT       int    `json:"hc_idle_conn_timeout"`
+	HC_MAX_RETRIES             int    `json:"hc_max_retries"`
+	HC_CB_FAILURE_THRESHOLD    int    `json:"hc_cb_failure_threshold"`
+	HC_CB_OPEN_TIMEOUT         int    `json:"hc_cb_open_timeout"`
+}
+
 var default_conf *Config
 
 func NewDefaultConf() *Config {
@@ -148,6 +189,11 @@ func (c *Config) Reload() {
 	c.setAppTargetDeploy(c.AppTargetDeploy)
 }
 
+// SetAppLogLevel altera o nível de log global em tempo de execução
+func (c *Config) SetAppLogLevel(level string) {
+	c.setAppLogLevel(level)
+}
+
 func (c *Config) setAppLogLevel(level string) {
 	switch strings.ToUpper(level) {
 	case "TRACE":
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 	"github.com/faelp22/go-commons-libs/core/config"
 	"github.com/phuslu/log"
@@ -32,6 +33,7 @@ type BlobInterface interface {
 	CreateBlockBlobClient(fileName, containerName string) (*blockblob.Client, error)
 	PutBlock(ctx context.Context, blockBlockClient *blockblob.Client, blockID uint16, data *[]byte) (string, error)
 	MountFile(ctx context.Context, blockBlobClient *blockblob.Client, blockIDs *[]string) error
+	MountFileWithHeaders(ctx context.Context, blockBlobClient *blockblob.Client, blockIDs *[]string, headers *blob.HTTPHeaders) error
 	// desabilitado
 	// createContainer(ctx context.Context, containerName string) error
 }
@@ -232,6 +232,19 @@ func (bs *blobStorage) MountFile(ctx context.Context, blockBlobClient *blockblob
 	return nil
 }
 
+// MountFileWithHeaders works like MountFile but also sets the blob HTTP headers
+// (content type, MD5, etc) in the same commit, so the blob never exists without them
+func (bs *blobStorage) MountFileWithHeaders(ctx context.Context, blockBlobClient *blockblob.Client, blockIDs *[]string, headers *blob.HTTPHeaders) error {
+	_, err := blockBlobClient.CommitBlockList(ctx, *blockIDs, &blockblob.CommitBlockListOptions{
+		HTTPHeaders: headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 //func (bs *blobStorage) createContainer(ctx context.Context, containerName string) error {
 //	if _, err := bs.Client.CreateContainer(ctx, containerName, nil); err != nil {
 //		return fmt.Errorf("error creating a blob container: %s", err.Error())
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/phuslu/log"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuit struct {
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// circuitBreaker mantém um circuito por host. Depois de threshold falhas
+// consecutivas (erro de rede ou 5xx) o circuito abre e as chamadas falham com
+// ErrCircuitOpen; passado o openTimeout uma única requisição de teste é liberada.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	circuits    map[string]*circuit
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		circuits:    map[string]*circuit{},
+	}
+}
+
+func (cb *circuitBreaker) get(host string) *circuit {
+	c, ok := cb.circuits[host]
+	if !ok {
+		c = &circuit{}
+		cb.circuits[host] = c
+	}
+	return c
+}
+
+func (cb *circuitBreaker) allow(host string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.get(host)
+
+	switch c.state {
+	case circuitOpen:
+		if time.Since(c.openedAt) < cb.openTimeout {
+			return ErrCircuitOpen
+		}
+		c.state = circuitHalfOpen
+		c.probing = true
+		return nil
+	case circuitHalfOpen:
+		if c.probing {
+			return ErrCircuitOpen
+		}
+		c.probing = true
+	}
+
+	return nil
+}
+
+// release libera a requisição de teste sem alterar o estado do circuito
+func (cb *circuitBreaker) release(host string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.get(host).probing = false
+}
+
+func (cb *circuitBreaker) done(host string, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	c := cb.get(host)
+	c.probing = false
+
+	if success {
+		if c.state != circuitClosed {
+			log.Info().Str("FunctionName", "circuitBreaker").Str("Host", host).Msg("Circuit closed")
+		}
+		c.state = circuitClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == circuitHalfOpen || c.failures >= cb.threshold {
+		if c.state != circuitOpen {
+			log.Warn().Str("FunctionName", "circuitBreaker").Str("Host", host).Int("Failures", c.failures).Msg("Circuit opened")
+		}
+		c.state = circuitOpen
+		c.openedAt = time.Now()
+	}
+}
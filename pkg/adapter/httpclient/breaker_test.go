@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const host = "api.local"
+	cb := newCircuitBreaker(2, 20*time.Millisecond)
+
+	cb.done(host, false)
+	if err := cb.allow(host); err != nil {
+		t.Fatalf("allow after 1 failure = %v", err)
+	}
+
+	cb.done(host, false)
+	if err := cb.allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow after threshold = %v, want ErrCircuitOpen", err)
+	}
+	if err := cb.allow("other.local"); err != nil {
+		t.Fatalf("other host = %v, circuits must be per host", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Apenas uma requisição de teste é liberada no half-open
+	if err := cb.allow(host); err != nil {
+		t.Fatalf("probe = %v", err)
+	}
+	if err := cb.allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// Falha no teste reabre o circuito
+	cb.done(host, false)
+	if err := cb.allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow after failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := cb.allow(host); err != nil {
+		t.Fatalf("probe = %v", err)
+	}
+
+	// release devolve a vaga de teste sem mudar o estado
+	cb.release(host)
+	if err := cb.allow(host); err != nil {
+		t.Fatalf("probe after release = %v", err)
+	}
+
+	cb.done(host, true)
+	for i := 0; i < 3; i++ {
+		if err := cb.allow(host); err != nil {
+			t.Fatalf("allow after close = %v", err)
+		}
+	}
+}
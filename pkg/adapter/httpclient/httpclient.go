@@ -0,0 +1,391 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_HC_TIMEOUT                 = 30  // segundos
+	DEFAULT_HC_MAX_IDLE_CONNS          = 100 // conexões
+	DEFAULT_HC_MAX_IDLE_CONNS_PER_HOST = 10  // conexões
+	DEFAULT_HC_IDLE_CONN_TIMEOUT       = 90  // segundos
+	DEFAULT_HC_MAX_RETRIES             = 2   // tentativas além da primeira
+	DEFAULT_HC_CB_FAILURE_THRESHOLD    = 5   // falhas consecutivas
+	DEFAULT_HC_CB_OPEN_TIMEOUT         = 30  // segundos
+
+	DEFAULT_RETRY_BASE_DELAY = 100 * time.Millisecond
+	DEFAULT_RETRY_MAX_DELAY  = 2 * time.Second
+
+	// MAX_ERROR_BODY_SIZE quantidade máxima do corpo guardada no HttpError
+	MAX_ERROR_BODY_SIZE = 64 * 1024
+
+	CONTENT_TYPE_JSON = "application/json"
+)
+
+// HttpError resposta com status fora da faixa 2xx
+type HttpError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       []byte
+}
+
+func (e *HttpError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type HttpClientInterface interface {
+	// GetClient retorna o *http.Client sem retry, circuit breaker ou propagação
+	GetClient() *http.Client
+	// Do envia a requisição aplicando circuit breaker, retry, propagação de
+	// X-Request-Id/traceparent e log. Caminhos relativos usam o HC_BASE_URL.
+	Do(req *http.Request) (*http.Response, error)
+	// DoJSON envia in como JSON (quando não for nil) e decodifica a resposta em
+	// out (quando não for nil). Status fora de 2xx retornam *HttpError.
+	DoJSON(ctx context.Context, method, path string, in, out interface{}) error
+	GetJSON(ctx context.Context, path string, out interface{}) error
+	PostJSON(ctx context.Context, path string, in, out interface{}) error
+	PutJSON(ctx context.Context, path string, in, out interface{}) error
+	PatchJSON(ctx context.Context, path string, in, out interface{}) error
+	DeleteJSON(ctx context.Context, path string, out interface{}) error
+}
+
+type http_client struct {
+	client  *http.Client
+	cfg     *config.HttpClientConfig
+	breaker *circuitBreaker
+	logger  *log.Logger
+}
+
+// New cria um cliente HTTP a partir do Config.
+//
+// Variáveis de ambiente:
+//
+//	SRV_HC_BASE_URL                 URL base usada nos caminhos relativos
+//	SRV_HC_TIMEOUT                  timeout de cada tentativa em segundos (padrão: 30)
+//	SRV_HC_MAX_IDLE_CONNS           máximo de conexões ociosas (padrão: 100)
+//	SRV_HC_MAX_IDLE_CONNS_PER_HOST  máximo de conexões ociosas por host (padrão: 10)
+//	SRV_HC_IDLE_CONN_TIMEOUT        tempo em segundos até fechar uma conexão ociosa (padrão: 90)
+//	SRV_HC_MAX_RETRIES              novas tentativas em métodos idempotentes (padrão: 2)
+//	SRV_HC_CB_FAILURE_THRESHOLD     falhas consecutivas que abrem o circuito (padrão: 5)
+//	SRV_HC_CB_OPEN_TIMEOUT          segundos com o circuito aberto antes de testar o host (padrão: 30)
+//
+// Para chamar mais de um serviço use NewWithConfig com um HttpClientConfig para cada um.
+//
+// Exemplo de Uso:
+//
+//	client := httpclient.New(conf)
+//
+//	var user User
+//	err := client.GetJSON(r.Context(), "/api/v1/users/"+id, &user)
+//	var httpErr *httpclient.HttpError
+//	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+//	    ...
+//	}
+func New(conf *config.Config) HttpClientInterface {
+	if conf.HttpClientConfig == nil {
+		conf.HttpClientConfig = &config.HttpClientConfig{}
+	}
+
+	SRV_HC_BASE_URL := os.Getenv("SRV_HC_BASE_URL")
+	if SRV_HC_BASE_URL != "" {
+		conf.HC_BASE_URL = SRV_HC_BASE_URL
+	}
+
+	conf.HC_TIMEOUT = envInt("SRV_HC_TIMEOUT", conf.HC_TIMEOUT)
+	conf.HC_MAX_IDLE_CONNS = envInt("SRV_HC_MAX_IDLE_CONNS", conf.HC_MAX_IDLE_CONNS)
+	conf.HC_MAX_IDLE_CONNS_PER_HOST = envInt("SRV_HC_MAX_IDLE_CONNS_PER_HOST", conf.HC_MAX_IDLE_CONNS_PER_HOST)
+	conf.HC_IDLE_CONN_TIMEOUT = envInt("SRV_HC_IDLE_CONN_TIMEOUT", conf.HC_IDLE_CONN_TIMEOUT)
+	conf.HC_MAX_RETRIES = envInt("SRV_HC_MAX_RETRIES", conf.HC_MAX_RETRIES)
+	conf.HC_CB_FAILURE_THRESHOLD = envInt("SRV_HC_CB_FAILURE_THRESHOLD", conf.HC_CB_FAILURE_THRESHOLD)
+	conf.HC_CB_OPEN_TIMEOUT = envInt("SRV_HC_CB_OPEN_TIMEOUT", conf.HC_CB_OPEN_TIMEOUT)
+
+	return NewWithConfig(conf, conf.HttpClientConfig)
+}
+
+// NewWithConfig cria um cliente HTTP sem ler as variáveis de ambiente. Campos
+// zerados usam os valores DEFAULT_HC_*; HC_MAX_RETRIES negativo desabilita o retry.
+func NewWithConfig(conf *config.Config, cfg *config.HttpClientConfig) HttpClientInterface {
+	if cfg.HC_TIMEOUT <= 0 {
+		cfg.HC_TIMEOUT = DEFAULT_HC_TIMEOUT
+	}
+	if cfg.HC_MAX_IDLE_CONNS <= 0 {
+		cfg.HC_MAX_IDLE_CONNS = DEFAULT_HC_MAX_IDLE_CONNS
+	}
+	if cfg.HC_MAX_IDLE_CONNS_PER_HOST <= 0 {
+		cfg.HC_MAX_IDLE_CONNS_PER_HOST = DEFAULT_HC_MAX_IDLE_CONNS_PER_HOST
+	}
+	if cfg.HC_IDLE_CONN_TIMEOUT <= 0 {
+		cfg.HC_IDLE_CONN_TIMEOUT = DEFAULT_HC_IDLE_CONN_TIMEOUT
+	}
+	if cfg.HC_MAX_RETRIES == 0 {
+		cfg.HC_MAX_RETRIES = DEFAULT_HC_MAX_RETRIES
+	}
+	if cfg.HC_CB_FAILURE_THRESHOLD <= 0 {
+		cfg.HC_CB_FAILURE_THRESHOLD = DEFAULT_HC_CB_FAILURE_THRESHOLD
+	}
+	if cfg.HC_CB_OPEN_TIMEOUT <= 0 {
+		cfg.HC_CB_OPEN_TIMEOUT = DEFAULT_HC_CB_OPEN_TIMEOUT
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.HC_MAX_IDLE_CONNS
+	transport.MaxIdleConnsPerHost = cfg.HC_MAX_IDLE_CONNS_PER_HOST
+	transport.IdleConnTimeout = time.Duration(cfg.HC_IDLE_CONN_TIMEOUT) * time.Second
+
+	logger := conf.GetGlobalLogger()
+	if logger == nil {
+		logger = &log.DefaultLogger
+	}
+
+	return &http_client{
+		client: &http.Client{
+			Timeout:   time.Duration(cfg.HC_TIMEOUT) * time.Second,
+			Transport: transport,
+		},
+		cfg:     cfg,
+		breaker: newCircuitBreaker(cfg.HC_CB_FAILURE_THRESHOLD, time.Duration(cfg.HC_CB_OPEN_TIMEOUT)*time.Second),
+		logger:  logger,
+	}
+}
+
+func envInt(name string, current int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return current
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Error().Str(name, "Invalid value").Str("SetDefaultValue", "default").Msg(err.Error())
+		return 0
+	}
+
+	return n
+}
+
+func (hc *http_client) GetClient() *http.Client {
+	return hc.client
+}
+
+func (hc *http_client) Do(req *http.Request) (*http.Response, error) {
+	if !req.URL.IsAbs() && hc.cfg.HC_BASE_URL != "" {
+		u, err := url.Parse(joinURL(hc.cfg.HC_BASE_URL, req.URL.String()))
+		if err != nil {
+			return nil, err
+		}
+		req.URL = u
+		req.Host = u.Host
+	}
+
+	injectPropagation(req)
+
+	maxRetries := hc.cfg.HC_MAX_RETRIES
+	if maxRetries < 0 || !retryable(req) {
+		maxRetries = 0
+	}
+
+	host := req.URL.Host
+
+	for attempt := 0; ; attempt++ {
+		if err := hc.breaker.allow(host); err != nil {
+			hc.logger.Error().Str("FunctionName", "httpclient.Do").Str("Method", req.Method).Str("Host", host).Str("Path", req.URL.Path).Str("RequestId", req.Header.Get(HEADER_REQUEST_ID)).Msg(err.Error())
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		start := time.Now()
+		resp, err := hc.client.Do(req)
+		duration := time.Since(start)
+
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if err != nil && req.Context().Err() != nil {
+			// Cancelamento pelo chamador não indica problema no host
+			hc.breaker.release(host)
+		} else {
+			hc.breaker.done(host, !failed)
+		}
+
+		entry := hc.logger.Info()
+		if failed {
+			entry = hc.logger.Warn()
+		}
+		entry = entry.Str("Method", req.Method).
+			Str("Host", host).
+			Str("Path", req.URL.Path).
+			Str("RequestId", req.Header.Get(HEADER_REQUEST_ID)).
+			Int("Attempt", attempt+1).
+			Dur("Duration", duration)
+		if resp != nil {
+			entry = entry.Int("StatusCode", resp.StatusCode)
+		}
+		if err != nil {
+			entry.Str("Erro", err.Error()).Msg("HTTP Client Request")
+		} else {
+			entry.Msg(http.StatusText(resp.StatusCode))
+		}
+
+		if attempt >= maxRetries || !shouldRetry(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		wait := backoff(attempt, resp)
+		if resp != nil {
+			// Libera a conexão para ser reutilizada na próxima tentativa
+			io.Copy(io.Discard, io.LimitReader(resp.Body, MAX_ERROR_BODY_SIZE))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (hc *http_client) DoJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", CONTENT_TYPE_JSON)
+	if in != nil {
+		req.Header.Set("Content-Type", CONTENT_TYPE_JSON)
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, MAX_ERROR_BODY_SIZE))
+		return &HttpError{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Body:       data,
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		log.Error().Str("FunctionName", "DoJSON").Str("Method", req.Method).Str("URL", req.URL.String()).Msg(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (hc *http_client) GetJSON(ctx context.Context, path string, out interface{}) error {
+	return hc.DoJSON(ctx, http.MethodGet, path, nil, out)
+}
+
+func (hc *http_client) PostJSON(ctx context.Context, path string, in, out interface{}) error {
+	return hc.DoJSON(ctx, http.MethodPost, path, in, out)
+}
+
+func (hc *http_client) PutJSON(ctx context.Context, path string, in, out interface{}) error {
+	return hc.DoJSON(ctx, http.MethodPut, path, in, out)
+}
+
+func (hc *http_client) PatchJSON(ctx context.Context, path string, in, out interface{}) error {
+	return hc.DoJSON(ctx, http.MethodPatch, path, in, out)
+}
+
+func (hc *http_client) DeleteJSON(ctx context.Context, path string, out interface{}) error {
+	return hc.DoJSON(ctx, http.MethodDelete, path, nil, out)
+}
+
+func joinURL(base, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// retryable apenas métodos idempotentes são repetidos. POST e PATCH são
+// repetidos quando enviam o header Idempotency-Key.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// backoff exponencial com full jitter, respeitando o Retry-After quando informado
+func backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if wait := time.Duration(seconds) * time.Second; wait <= DEFAULT_RETRY_MAX_DELAY {
+				return wait
+			}
+			return DEFAULT_RETRY_MAX_DELAY
+		}
+	}
+
+	wait := DEFAULT_RETRY_BASE_DELAY << attempt
+	if wait <= 0 || wait > DEFAULT_RETRY_MAX_DELAY {
+		wait = DEFAULT_RETRY_MAX_DELAY
+	}
+
+	return time.Duration(rand.Int64N(int64(wait)) + 1)
+}
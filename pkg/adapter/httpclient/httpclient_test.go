@@ -0,0 +1,237 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+)
+
+// TestMain reduz o log das requisições feitas nos testes
+func TestMain(m *testing.M) {
+	config.NewDefaultConf().SetAppLogLevel("error")
+	os.Exit(m.Run())
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, cfg *config.HttpClientConfig) (HttpClientInterface, *httptest.Server) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	if cfg == nil {
+		cfg = &config.HttpClientConfig{}
+	}
+	cfg.HC_BASE_URL = srv.URL
+
+	return NewWithConfig(&config.Config{}, cfg), srv
+}
+
+// statusSequence responde com os status informados, repetindo o último
+func statusSequence(calls *atomic.Int32, bodies *[]string, statuses ...int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		if bodies != nil {
+			data, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(data))
+		}
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(statuses[n])
+	}
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		idempotencyKey string
+		statuses       []int
+		maxRetries     int
+		wantCalls      int32
+		wantStatus     int
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantCalls: 1, wantStatus: 200},
+		{name: "retry then success", method: http.MethodGet, statuses: []int{503, 502, 200}, wantCalls: 3, wantStatus: 200},
+		{name: "retries exhausted", method: http.MethodGet, statuses: []int{503}, wantCalls: 3, wantStatus: 503},
+		{name: "custom retries", method: http.MethodGet, statuses: []int{429}, maxRetries: 1, wantCalls: 2, wantStatus: 429},
+		{name: "retry disabled", method: http.MethodGet, statuses: []int{503}, maxRetries: -1, wantCalls: 1, wantStatus: 503},
+		{name: "500 not retried", method: http.MethodGet, statuses: []int{500}, wantCalls: 1, wantStatus: 500},
+		{name: "4xx not retried", method: http.MethodGet, statuses: []int{404}, wantCalls: 1, wantStatus: 404},
+		{name: "post not retried", method: http.MethodPost, statuses: []int{503}, wantCalls: 1, wantStatus: 503},
+		{name: "post with idempotency key", method: http.MethodPost, idempotencyKey: "k1", statuses: []int{503, 201}, wantCalls: 2, wantStatus: 201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var bodies []string
+			client, _ := newTestClient(t, statusSequence(&calls, &bodies, tt.statuses...), &config.HttpClientConfig{HC_MAX_RETRIES: tt.maxRetries})
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(`{"id":1}`)
+			}
+			req, _ := http.NewRequest(tt.method, "/items", body)
+			if tt.idempotencyKey != "" {
+				req.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+			if tt.method == http.MethodPost {
+				for i, b := range bodies {
+					if b != `{"id":1}` {
+						t.Errorf("attempt %d body = %q", i+1, b)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDoCircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	client, _ := newTestClient(t, statusSequence(&calls, nil, 500), &config.HttpClientConfig{HC_CB_FAILURE_THRESHOLD: 2})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if _, err := client.Do(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestDoJSON(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/items/1":
+			if r.Header.Get("Accept") != CONTENT_TYPE_JSON {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Write([]byte(`{"id":1,"name":"a"}`))
+		case "/api/items":
+			if r.Header.Get("Content-Type") != CONTENT_TYPE_JSON {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(data)
+		case "/api/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/invalid":
+			w.Write([]byte(`{"id":`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"msg":"not found"}`))
+		}
+	}, &config.HttpClientConfig{HC_MAX_RETRIES: -1})
+
+	ctx := context.Background()
+
+	var got item
+	if err := client.GetJSON(ctx, "/api/items/1", &got); err != nil || got.Name != "a" {
+		t.Fatalf("GetJSON = %+v, %v", got, err)
+	}
+
+	var created item
+	if err := client.PostJSON(ctx, "api/items", item{ID: 2, Name: "b"}, &created); err != nil || created.ID != 2 {
+		t.Fatalf("PostJSON = %+v, %v", created, err)
+	}
+
+	if err := client.DeleteJSON(ctx, "/api/empty", &got); err != nil {
+		t.Fatalf("DeleteJSON = %v", err)
+	}
+
+	if err := client.GetJSON(ctx, "/api/invalid", &got); err == nil {
+		t.Fatal("expected decode error")
+	}
+
+	err := client.GetJSON(ctx, "/api/missing", &got)
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want *HttpError", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound || string(httpErr.Body) != `{"msg":"not found"}` {
+		t.Errorf("httpErr = %d %s", httpErr.StatusCode, httpErr.Body)
+	}
+
+	if err := client.PostJSON(ctx, "/api/items", make(chan int), nil); err == nil {
+		t.Fatal("expected marshal error")
+	}
+}
+
+func TestDoCanceledContext(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {}, &config.HttpClientConfig{HC_CB_FAILURE_THRESHOLD: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	// O cancelamento não conta como falha do host
+	req, _ = http.NewRequest(http.MethodGet, "/", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("err = %v, circuit should still be closed", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewEnv(t *testing.T) {
+	t.Setenv("SRV_HC_BASE_URL", "http://api.local")
+	t.Setenv("SRV_HC_TIMEOUT", "5")
+	t.Setenv("SRV_HC_MAX_RETRIES", "x")
+
+	conf := &config.Config{}
+	New(conf)
+
+	if conf.HC_BASE_URL != "http://api.local" {
+		t.Errorf("base url = %q", conf.HC_BASE_URL)
+	}
+	if conf.HC_TIMEOUT != 5 {
+		t.Errorf("timeout = %d", conf.HC_TIMEOUT)
+	}
+	if conf.HC_MAX_RETRIES != DEFAULT_HC_MAX_RETRIES {
+		t.Errorf("max retries = %d, want default", conf.HC_MAX_RETRIES)
+	}
+}
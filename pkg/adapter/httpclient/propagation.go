@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HEADER_REQUEST_ID   = "X-Request-Id"
+	HEADER_TRACE_PARENT = "traceparent"
+)
+
+type requestIDKey struct{}
+type traceParentKey struct{}
+
+// WithRequestID guarda o X-Request-Id no contexto para ser enviado nas chamadas
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retorna o X-Request-Id guardado no contexto
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTraceParent guarda o traceparent (W3C Trace Context) no contexto
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// TraceParent retorna o traceparent guardado no contexto
+func TraceParent(ctx context.Context) string {
+	tp, _ := ctx.Value(traceParentKey{}).(string)
+	return tp
+}
+
+// PropagationMiddleware lê o X-Request-Id e o traceparent da requisição
+// recebida (gerando um X-Request-Id quando ausente) e os guarda no contexto,
+// assim as chamadas feitas com o httpclient usando r.Context() continuam o
+// mesmo rastreio. O X-Request-Id também é devolvido na resposta.
+//
+// Exemplo de Uso:
+//
+//	router.Use(httpclient.PropagationMiddleware)
+func PropagationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HEADER_REQUEST_ID)
+		if id == "" {
+			id = uuid.New().String()
+		}
+		w.Header().Set(HEADER_REQUEST_ID, id)
+
+		ctx := WithRequestID(r.Context(), id)
+		if tp := r.Header.Get(HEADER_TRACE_PARENT); validTraceParent(tp) {
+			ctx = WithTraceParent(ctx, tp)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// injectPropagation adiciona os headers na requisição de saída. O traceparent
+// mantém o trace-id recebido e usa um novo parent-id para esta chamada.
+func injectPropagation(req *http.Request) {
+	ctx := req.Context()
+
+	if req.Header.Get(HEADER_REQUEST_ID) == "" {
+		id := RequestID(ctx)
+		if id == "" {
+			id = uuid.New().String()
+		}
+		req.Header.Set(HEADER_REQUEST_ID, id)
+	}
+
+	if req.Header.Get(HEADER_TRACE_PARENT) == "" {
+		traceID, flags := randomHex(16), "01"
+		if tp := TraceParent(ctx); validTraceParent(tp) {
+			parts := strings.Split(tp, "-")
+			traceID, flags = parts[1], parts[3]
+		}
+		req.Header.Set(HEADER_TRACE_PARENT, "00-"+traceID+"-"+randomHex(8)+"-"+flags)
+	}
+}
+
+// validTraceParent valida o formato version-traceid-parentid-flags
+func validTraceParent(tp string) bool {
+	parts := strings.Split(tp, "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return false
+	}
+	for _, p := range parts {
+		if _, err := hex.DecodeString(p); err != nil {
+			return false
+		}
+	}
+	return parts[1] != strings.Repeat("0", 32) && parts[2] != strings.Repeat("0", 16)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
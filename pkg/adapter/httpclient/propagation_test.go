@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestValidTraceParent(t *testing.T) {
+	tests := []struct {
+		tp   string
+		want bool
+	}{
+		{tp: testTraceParent, want: true},
+		{tp: "", want: false},
+		{tp: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", want: false},
+		{tp: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", want: false},
+		{tp: "00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: false},
+		{tp: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", want: false},
+		{tp: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := validTraceParent(tt.tp); got != tt.want {
+			t.Errorf("validTraceParent(%q) = %v, want %v", tt.tp, got, tt.want)
+		}
+	}
+}
+
+func TestPropagationMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestID   string
+		traceParent string
+		wantTP      string
+	}{
+		{name: "generates request id"},
+		{name: "keeps request id", requestID: "req-1"},
+		{name: "keeps valid traceparent", requestID: "req-1", traceParent: testTraceParent, wantTP: testTraceParent},
+		{name: "drops invalid traceparent", traceParent: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID, gotTP string
+			h := PropagationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotID = RequestID(r.Context())
+				gotTP = TraceParent(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestID != "" {
+				r.Header.Set(HEADER_REQUEST_ID, tt.requestID)
+			}
+			if tt.traceParent != "" {
+				r.Header.Set(HEADER_TRACE_PARENT, tt.traceParent)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if gotID == "" || (tt.requestID != "" && gotID != tt.requestID) {
+				t.Errorf("request id = %q, want %q", gotID, tt.requestID)
+			}
+			if w.Header().Get(HEADER_REQUEST_ID) != gotID {
+				t.Errorf("response request id = %q, want %q", w.Header().Get(HEADER_REQUEST_ID), gotID)
+			}
+			if gotTP != tt.wantTP {
+				t.Errorf("traceparent = %q, want %q", gotTP, tt.wantTP)
+			}
+		})
+	}
+}
+
+func TestInjectPropagation(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := WithTraceParent(WithRequestID(r.Context(), "req-1"), testTraceParent)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.local/", nil)
+
+	injectPropagation(req)
+
+	if got := req.Header.Get(HEADER_REQUEST_ID); got != "req-1" {
+		t.Errorf("request id = %q", got)
+	}
+
+	tp := req.Header.Get(HEADER_TRACE_PARENT)
+	parts := strings.Split(tp, "-")
+	if !validTraceParent(tp) || parts[1] != "4bf92f3577b34da6a3ce929d0e0e4736" || parts[3] != "01" {
+		t.Errorf("traceparent = %q, want same trace id and flags", tp)
+	}
+	if parts[2] == "00f067aa0ba902b7" {
+		t.Error("parent id must be new for the outgoing call")
+	}
+
+	// Headers informados pelo chamador são mantidos
+	req, _ = http.NewRequest(http.MethodGet, "http://api.local/", nil)
+	req.Header.Set(HEADER_REQUEST_ID, "custom")
+	injectPropagation(req)
+	if req.Header.Get(HEADER_REQUEST_ID) != "custom" {
+		t.Errorf("request id = %q", req.Header.Get(HEADER_REQUEST_ID))
+	}
+	if !validTraceParent(req.Header.Get(HEADER_TRACE_PARENT)) {
+		t.Errorf("traceparent = %q, want a new valid one", req.Header.Get(HEADER_TRACE_PARENT))
+	}
+}
@@ -0,0 +1,344 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/gorilla/mux"
+	"github.com/phuslu/log"
+)
+
+const (
+	// DEFAULT_ADMIN_HOST mantém o servidor admin acessível apenas pela própria máquina
+	DEFAULT_ADMIN_HOST             = "127.0.0.1"
+	DEFAULT_ADMIN_SHUTDOWN_TIMEOUT = 10 * time.Second
+	DEFAULT_HEALTH_CHECK_TIMEOUT   = 5 * time.Second
+)
+
+// DefaultAdminConfigRedactKeys trechos de chave cujo valor é mascarado no /config
+var DefaultAdminConfigRedactKeys = []string{"pass", "secret", "token", "key", "uri", "dsn"}
+
+// HealthCheck verificação executada no /health/ready do servidor admin
+type HealthCheck func(ctx context.Context) error
+
+var (
+	healthChecksMu sync.RWMutex
+	healthChecks   = map[string]HealthCheck{}
+
+	adminStartedAt = time.Now()
+	adminMetrics   = &httpMetrics{}
+)
+
+// RegisterHealthCheck adiciona uma verificação ao /health/ready do servidor admin.
+//
+// Exemplo de Uso:
+//
+//	httpserver.RegisterHealthCheck("redis", func(ctx context.Context) error {
+//	    return redisConn.GetClient().Ping(ctx).Err()
+//	})
+func RegisterHealthCheck(name string, check HealthCheck) {
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+	healthChecks[name] = check
+}
+
+func loadAdminConfig(conf *config.Config) {
+	SRV_HTTP_ADMIN_PORT := os.Getenv("SRV_HTTP_ADMIN_PORT")
+	if SRV_HTTP_ADMIN_PORT != "" {
+		conf.ADMIN_PORT = SRV_HTTP_ADMIN_PORT
+	}
+
+	SRV_HTTP_ADMIN_HOST := os.Getenv("SRV_HTTP_ADMIN_HOST")
+	if SRV_HTTP_ADMIN_HOST != "" {
+		conf.ADMIN_HOST = SRV_HTTP_ADMIN_HOST
+	} else if conf.ADMIN_HOST == "" {
+		conf.ADMIN_HOST = DEFAULT_ADMIN_HOST
+	}
+}
+
+// startAdminServer sobe o servidor admin em SRV_HTTP_ADMIN_HOST:SRV_HTTP_ADMIN_PORT
+// e o encerra junto com o Shutdown do servidor principal.
+//
+// O host padrão é 127.0.0.1; use SRV_HTTP_ADMIN_HOST=0.0.0.0 para expor as
+// rotas fora da máquina, por exemplo para o probe do Kubernetes.
+//
+// Rotas:
+//
+//	GET  /health/live     o processo está de pé
+//	GET  /health/ready    executa as verificações do RegisterHealthCheck
+//	GET  /metrics         métricas no formato texto do Prometheus
+//	GET  /debug/vars      expvar
+//	GET  /debug/pprof/    pprof
+//	GET  /config          configuração atual, com as chaves sensíveis mascaradas
+//	GET  /loglevel        nível de log atual
+//	PUT  /loglevel        altera o nível de log, body: {"level": "debug"}
+//	POST /gc              executa o garbage collector
+//	POST /cors/reload     reaplica os campos CORS_* do HttpConfig atual
+func startAdminServer(srv *http.Server, conf *config.Config) {
+	var shuttingDown atomic.Bool
+
+	admin := &http.Server{
+		ReadTimeout: 10 * time.Second,
+		Addr:        net.JoinHostPort(conf.ADMIN_HOST, conf.ADMIN_PORT),
+		Handler:     newAdminRouter(conf, &shuttingDown),
+		ErrorLog:    log.DefaultLogger.Std("", 0),
+	}
+
+	srv.RegisterOnShutdown(func() {
+		shuttingDown.Store(true)
+
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_ADMIN_SHUTDOWN_TIMEOUT)
+		defer cancel()
+
+		if err := admin.Shutdown(ctx); err != nil {
+			log.Error().Str("FunctionName", "startAdminServer").Msg(err.Error())
+		}
+	})
+
+	go func() {
+		log.Info().Str("AdminAddr", admin.Addr).Msg("Admin Server Run")
+		if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error().Str("FunctionName", "startAdminServer").Str("AdminAddr", admin.Addr).Msg(err.Error())
+		}
+	}()
+}
+
+func newAdminRouter(conf *config.Config, shuttingDown *atomic.Bool) *mux.Router {
+	r := mux.NewRouter()
+
+	r.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		if shuttingDown.Load() {
+			WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+			return
+		}
+
+		status, checks := runHealthChecks(r.Context())
+		WriteJSON(w, status, map[string]interface{}{"status": http.StatusText(status), "checks": checks})
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/metrics", adminMetrics.writePrometheus).Methods(http.MethodGet)
+	r.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
+
+	r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	r.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+
+	r.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, redactedConfig(conf))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/loglevel", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]string{"level": conf.AppLogLevel})
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/loglevel", func(w http.ResponseWriter, r *http.Request) {
+		body, err := DecodeJSON[struct {
+			Level string `json:"level" validate:"required,enum=trace|debug|info|warn|error|fatal|panic"`
+		}](r, nil)
+		if err == nil {
+			// O SetAppLogLevel não diferencia maiúsculas, então "DEBUG" também é aceito
+			body.Level = strings.ToLower(body.Level)
+			err = validateDecoded(&body)
+		}
+		if err != nil {
+			WriteProblem(w, r, err)
+			return
+		}
+
+		conf.SetAppLogLevel(body.Level)
+		log.Info().Str("FunctionName", "adminLogLevel").Str("LogLevel", conf.AppLogLevel).Msg("Log level changed")
+		WriteJSON(w, http.StatusOK, map[string]string{"level": conf.AppLogLevel})
+	}).Methods(http.MethodPut)
+
+	r.HandleFunc("/gc", func(w http.ResponseWriter, r *http.Request) {
+		runtime.GC()
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/cors/reload", func(w http.ResponseWriter, r *http.Request) {
+		if err := ReloadCORS(conf.HttpConfig); err != nil {
+			WriteProblem(w, r, NewProblem(http.StatusBadRequest, err.Error()))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodPost)
+
+	return r
+}
+
+func runHealthChecks(ctx context.Context) (int, map[string]string) {
+	healthChecksMu.RLock()
+	defer healthChecksMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_HEALTH_CHECK_TIMEOUT)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		status = http.StatusOK
+		result = make(map[string]string, len(healthChecks))
+	)
+
+	for name, check := range healthChecks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+
+			msg := "ok"
+			if err := check(ctx); err != nil {
+				msg = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result[name] = msg
+			if msg != "ok" {
+				status = http.StatusServiceUnavailable
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return status, result
+}
+
+func redactedConfig(conf *config.Config) map[string]interface{} {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		log.Error().Str("FunctionName", "redactedConfig").Msg(err.Error())
+		return nil
+	}
+
+	var dump map[string]interface{}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		log.Error().Str("FunctionName", "redactedConfig").Msg(err.Error())
+		return nil
+	}
+
+	for key, value := range dump {
+		if value == nil || value == "" {
+			continue
+		}
+		for _, secret := range DefaultAdminConfigRedactKeys {
+			if strings.Contains(strings.ToLower(key), secret) {
+				dump[key] = LOG_REDACTED_VALUE
+				break
+			}
+		}
+	}
+
+	return dump
+}
+
+type httpMetricKey struct {
+	method string
+	code   int
+}
+
+type httpMetricValue struct {
+	count    atomic.Int64
+	duration atomic.Int64 // nanosegundos
+}
+
+// httpMetrics contadores das requisições do servidor principal
+type httpMetrics struct {
+	inFlight atomic.Int64
+	requests sync.Map // httpMetricKey -> *httpMetricValue
+}
+
+func (m *httpMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Add(1)
+		defer m.inFlight.Add(-1)
+
+		start := time.Now()
+		srw := &statusResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(srw, r)
+
+		status := srw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		v, _ := m.requests.LoadOrStore(httpMetricKey{method: metricMethod(r.Method), code: status}, &httpMetricValue{})
+		value := v.(*httpMetricValue)
+		value.count.Add(1)
+		value.duration.Add(int64(time.Since(start)))
+	})
+}
+
+// metricMethod evita que métodos arbitrários aumentem a cardinalidade das métricas
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+func (m *httpMetrics) writePrometheus(w http.ResponseWriter, r *http.Request) {
+	type sample struct {
+		key   httpMetricKey
+		value *httpMetricValue
+	}
+
+	var samples []sample
+	m.requests.Range(func(k, v interface{}) bool {
+		samples = append(samples, sample{key: k.(httpMetricKey), value: v.(*httpMetricValue)})
+		return true
+	})
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].key.method != samples[j].key.method {
+			return samples[i].key.method < samples[j].key.method
+		}
+		return samples[i].key.code < samples[j].key.code
+	})
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var b strings.Builder
+
+	b.WriteString("# HELP http_requests_total Total de requisições HTTP.\n# TYPE http_requests_total counter\n")
+	for _, s := range samples {
+		fmt.Fprintf(&b, "http_requests_total{method=%q,code=\"%d\"} %d\n", s.key.method, s.key.code, s.value.count.Load())
+	}
+
+	b.WriteString("# HELP http_request_duration_seconds Duração das requisições HTTP.\n# TYPE http_request_duration_seconds summary\n")
+	for _, s := range samples {
+		fmt.Fprintf(&b, "http_request_duration_seconds_sum{method=%q,code=\"%d\"} %g\n", s.key.method, s.key.code, time.Duration(s.value.duration.Load()).Seconds())
+		fmt.Fprintf(&b, "http_request_duration_seconds_count{method=%q,code=\"%d\"} %d\n", s.key.method, s.key.code, s.value.count.Load())
+	}
+
+	fmt.Fprintf(&b, "# HELP http_requests_in_flight Requisições HTTP em andamento.\n# TYPE http_requests_in_flight gauge\nhttp_requests_in_flight %d\n", m.inFlight.Load())
+	fmt.Fprintf(&b, "# HELP go_goroutines Quantidade de goroutines.\n# TYPE go_goroutines gauge\ngo_goroutines %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(&b, "# HELP go_memstats_heap_alloc_bytes Bytes alocados no heap.\n# TYPE go_memstats_heap_alloc_bytes gauge\ngo_memstats_heap_alloc_bytes %d\n", mem.HeapAlloc)
+	fmt.Fprintf(&b, "# HELP go_memstats_sys_bytes Bytes obtidos do sistema operacional.\n# TYPE go_memstats_sys_bytes gauge\ngo_memstats_sys_bytes %d\n", mem.Sys)
+	fmt.Fprintf(&b, "# HELP go_gc_cycles_total Ciclos do garbage collector.\n# TYPE go_gc_cycles_total counter\ngo_gc_cycles_total %d\n", mem.NumGC)
+	fmt.Fprintf(&b, "# HELP process_uptime_seconds Tempo desde o início do processo.\n# TYPE process_uptime_seconds gauge\nprocess_uptime_seconds %g\n", time.Since(adminStartedAt).Seconds())
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
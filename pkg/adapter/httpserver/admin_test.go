@@ -0,0 +1,172 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+)
+
+func TestLoadAdminConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		env      string
+		wantHost string
+	}{
+		{name: "default host", wantHost: DEFAULT_ADMIN_HOST},
+		{name: "code host kept", host: "10.0.0.1", wantHost: "10.0.0.1"},
+		{name: "env host", host: "10.0.0.1", env: "0.0.0.0", wantHost: "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SRV_HTTP_ADMIN_PORT", "9090")
+			t.Setenv("SRV_HTTP_ADMIN_HOST", tt.env)
+
+			conf := &config.Config{HttpConfig: &config.HttpConfig{ADMIN_HOST: tt.host}}
+			loadAdminConfig(conf)
+
+			if conf.ADMIN_HOST != tt.wantHost {
+				t.Errorf("host = %q, want %q", conf.ADMIN_HOST, tt.wantHost)
+			}
+			if conf.ADMIN_PORT != "9090" {
+				t.Errorf("port = %q", conf.ADMIN_PORT)
+			}
+		})
+	}
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	conf := config.NewDefaultConf()
+	prev := conf.AppLogLevel
+	t.Cleanup(func() { conf.SetAppLogLevel(prev) })
+
+	router := newAdminRouter(conf, &atomic.Bool{})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantLevel  string
+	}{
+		{name: "lowercase", body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantLevel: "debug"},
+		{name: "uppercase", body: `{"level":"WARN"}`, wantStatus: http.StatusOK, wantLevel: "warn"},
+		{name: "mixed case", body: `{"level":"Error"}`, wantStatus: http.StatusOK, wantLevel: "error"},
+		{name: "invalid", body: `{"level":"verbose"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "missing", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "malformed", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantLevel != "" && conf.AppLogLevel != tt.wantLevel {
+				t.Errorf("level = %q, want %q", conf.AppLogLevel, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestAdminHealthReady(t *testing.T) {
+	RegisterHealthCheck("test-ok", func(ctx context.Context) error { return nil })
+	t.Cleanup(func() {
+		healthChecksMu.Lock()
+		delete(healthChecks, "test-ok")
+		delete(healthChecks, "test-fail")
+		healthChecksMu.Unlock()
+	})
+
+	var shuttingDown atomic.Bool
+	router := newAdminRouter(&config.Config{HttpConfig: &config.HttpConfig{}}, &shuttingDown)
+
+	ready := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		return w.Code
+	}
+
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	RegisterHealthCheck("test-fail", func(ctx context.Context) error { return errors.New("down") })
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", code)
+	}
+
+	healthChecksMu.Lock()
+	delete(healthChecks, "test-fail")
+	healthChecksMu.Unlock()
+
+	shuttingDown.Store(true)
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 while shutting down", code)
+	}
+}
+
+func TestRedactedConfig(t *testing.T) {
+	conf := &config.Config{
+		AppName:       "app",
+		RedisDBConfig: &config.RedisDBConfig{RDB_HOST: "localhost", RDB_PASS: "secret"},
+		HttpConfig:    &config.HttpConfig{},
+	}
+
+	dump := redactedConfig(conf)
+	if dump["rdb_pass"] != LOG_REDACTED_VALUE {
+		t.Errorf("rdb_pass = %v", dump["rdb_pass"])
+	}
+	if dump["rdb_host"] != "localhost" {
+		t.Errorf("rdb_host = %v", dump["rdb_host"])
+	}
+}
+
+func TestAdminMetrics(t *testing.T) {
+	m := &httpMetrics{}
+	h := m.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, method := range []string{http.MethodPost, http.MethodPost, "BREW"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/", nil))
+	}
+
+	w := httptest.NewRecorder()
+	m.writePrometheus(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="POST",code="201"} 2`,
+		`http_requests_total{method="OTHER",code="201"} 1`,
+		"http_requests_in_flight 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestAdminGetLogLevel(t *testing.T) {
+	conf := config.NewDefaultConf()
+	w := httptest.NewRecorder()
+	newAdminRouter(conf, &atomic.Bool{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/loglevel", nil))
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["level"] != conf.AppLogLevel {
+		t.Errorf("level = %q, want %q", body["level"], conf.AppLogLevel)
+	}
+}
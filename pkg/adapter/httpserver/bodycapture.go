@@ -0,0 +1,252 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/phuslu/log"
+)
+
+const DEFAULT_BODY_CAPTURE_MAX_SIZE = 4 * 1024 // 4 KiB
+
+// DefaultBodyCaptureRedactKeys chaves JSON mascaradas quando RedactKeys está vazio
+var DefaultBodyCaptureRedactKeys = []string{"password", "senha", "cpf", "token", "access_token", "refresh_token", "secret", "authorization"}
+
+// BodyCaptureConfig configuração para o middleware de captura de corpo
+type BodyCaptureConfig struct {
+	// Routes prefixos ou globs dos paths capturados. Vazio captura todos.
+	Routes []string
+	// SampleRate fração (0 a 1) das requisições capturadas (padrão: 1, todas)
+	SampleRate float64
+	// MaxBodySize quantidade máxima de bytes registrada de cada corpo (padrão: 4KiB)
+	MaxBodySize int
+	// RedactKeys chaves JSON cujo valor é mascarado (padrão: DefaultBodyCaptureRedactKeys)
+	RedactKeys []string
+	// Force permite a captura com AppMode production
+	Force bool
+}
+
+// BodyCaptureMiddleware registra no log os corpos da requisição e da resposta
+// para depuração de integrações. Os corpos são truncados em MaxBodySize e as
+// chaves sensíveis de JSON são mascaradas.
+//
+// Com AppMode production o middleware não faz nada, a menos que Force seja true.
+//
+// Exemplo de Uso:
+//
+//	r.Use(httpserver.BodyCaptureMiddleware(conf, &httpserver.BodyCaptureConfig{
+//	    Routes:     []string{"/api/v1/integrations/"},
+//	    SampleRate: 0.1,
+//	    RedactKeys: []string{"password", "cpf", "token"},
+//	}))
+func BodyCaptureMiddleware(conf *config.Config, cfg *BodyCaptureConfig) func(http.Handler) http.Handler {
+	if cfg == nil {
+		cfg = &BodyCaptureConfig{}
+	}
+
+	if conf.AppMode == config.PRODUCTION && !cfg.Force {
+		log.Info().Str("FunctionName", "BodyCaptureMiddleware").Msg("Body capture is disabled in production mode, set Force to enable it")
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DEFAULT_BODY_CAPTURE_MAX_SIZE
+	}
+	if len(cfg.RedactKeys) == 0 {
+		cfg.RedactKeys = DefaultBodyCaptureRedactKeys
+	}
+
+	redactor := newBodyRedactor(cfg.RedactKeys)
+
+	logger := conf.GetGlobalLogger()
+	if conf.HttpConfig != nil && conf.HttpConfig.Logger != nil {
+		logger = conf.HttpConfig.Logger
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.shouldCapture(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reqBody := &limitedBuffer{max: cfg.MaxBodySize}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = &teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+			}
+
+			cw := &captureResponseWriter{ResponseWriter: w, body: &limitedBuffer{max: cfg.MaxBodySize}}
+			next.ServeHTTP(cw, r)
+
+			status := cw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			logger.Info().
+				Str("Method", r.Method).
+				Str("Path", r.URL.Path).
+				Int("StatusCode", status).
+				Str("RequestBody", redactor.redact(reqBody.buf.Bytes(), reqBody.truncated)).
+				Bool("RequestBodyTruncated", reqBody.truncated).
+				Str("ResponseBody", redactor.redact(cw.body.buf.Bytes(), cw.body.truncated)).
+				Bool("ResponseBodyTruncated", cw.body.truncated).
+				Msg("Body Capture")
+		})
+	}
+}
+
+func (cfg *BodyCaptureConfig) shouldCapture(r *http.Request) bool {
+	if len(cfg.Routes) > 0 {
+		matched := false
+		for _, route := range cfg.Routes {
+			if matchLogPath(route, r.URL.Path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return cfg.SampleRate >= 1 || rand.Float64() < cfg.SampleRate
+}
+
+type bodyRedactor struct {
+	keys    map[string]bool
+	pattern *regexp.Regexp
+}
+
+func newBodyRedactor(keys []string) *bodyRedactor {
+	br := &bodyRedactor{keys: map[string]bool{}}
+
+	quoted := make([]string, 0, len(keys))
+	for _, k := range keys {
+		br.keys[strings.ToLower(k)] = true
+		quoted = append(quoted, regexp.QuoteMeta(k))
+	}
+
+	// Usado quando o corpo não é um JSON válido, por exemplo quando foi truncado
+	br.pattern = regexp.MustCompile(fmt.Sprintf(`(?i)("(?:%s)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`, strings.Join(quoted, "|")))
+
+	return br
+}
+
+func (br *bodyRedactor) redact(body []byte, truncated bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if !truncated {
+		// UseNumber mantém IDs e valores acima de 2^53 sem passar por float64
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err == nil && dec.Decode(new(interface{})) == io.EOF {
+			if data, err := json.Marshal(br.walk(v)); err == nil {
+				return string(data)
+			}
+		}
+	}
+
+	return br.pattern.ReplaceAllString(string(body), `${1}"`+LOG_REDACTED_VALUE+`"`)
+}
+
+func (br *bodyRedactor) walk(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if br.keys[strings.ToLower(k)] {
+				val[k] = LOG_REDACTED_VALUE
+				continue
+			}
+			val[k] = br.walk(child)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = br.walk(child)
+		}
+	}
+	return v
+}
+
+// limitedBuffer guarda apenas os primeiros max bytes escritos
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := lb.max - lb.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			lb.buf.Write(p[:remaining])
+			lb.truncated = true
+		} else {
+			lb.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		lb.truncated = true
+	}
+	return len(p), nil
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type captureResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   *limitedBuffer
+}
+
+func (cw *captureResponseWriter) WriteHeader(status int) {
+	if cw.status == 0 {
+		cw.status = status
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *captureResponseWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
+
+// Hijack implementa http.Hijacker para suportar WebSocket upgrades
+func (cw *captureResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("responsewriter does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Flush implementa http.Flusher para suportar streaming
+func (cw *captureResponseWriter) Flush() {
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap permite que o http.ResponseController acesse o ResponseWriter original
+func (cw *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
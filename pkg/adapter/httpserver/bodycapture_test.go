@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/phuslu/log"
+)
+
+func newBodyCaptureTestConf(mode string) (*config.Config, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return &config.Config{
+		AppMode: mode,
+		HttpConfig: &config.HttpConfig{
+			Logger: &log.Logger{Level: log.InfoLevel, Writer: &log.IOWriter{Writer: &buf}},
+		},
+	}, &buf
+}
+
+func TestBodyCaptureMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		cfg         *BodyCaptureConfig
+		path        string
+		reqBody     string
+		wantCapture bool
+		wantReq     string
+		wantReqTrc  bool
+	}{
+		{name: "developer", mode: config.DEVELOPER, path: "/api", reqBody: `{"name":"ana"}`, wantCapture: true, wantReq: `{"name":"ana"}`},
+		{name: "production disabled", mode: config.PRODUCTION, path: "/api", reqBody: `{"name":"ana"}`},
+		{
+			name:        "production forced",
+			mode:        config.PRODUCTION,
+			cfg:         &BodyCaptureConfig{Force: true},
+			path:        "/api",
+			reqBody:     `{"name":"ana"}`,
+			wantCapture: true,
+			wantReq:     `{"name":"ana"}`,
+		},
+		{
+			name:        "route matched",
+			mode:        config.DEVELOPER,
+			cfg:         &BodyCaptureConfig{Routes: []string{"/api/*/integrations"}},
+			path:        "/api/v1/integrations",
+			reqBody:     `{}`,
+			wantCapture: true,
+			wantReq:     `{}`,
+		},
+		{
+			name:    "route not matched",
+			mode:    config.DEVELOPER,
+			cfg:     &BodyCaptureConfig{Routes: []string{"/api/*/integrations"}},
+			path:    "/api/v1/users",
+			reqBody: `{}`,
+		},
+		{
+			name:        "redacted",
+			mode:        config.DEVELOPER,
+			path:        "/login",
+			reqBody:     `{"user":"ana","Password":"x","items":[{"token":"t"}]}`,
+			wantCapture: true,
+			wantReq:     `{"Password":"` + LOG_REDACTED_VALUE + `","items":[{"token":"` + LOG_REDACTED_VALUE + `"}],"user":"ana"}`,
+		},
+		{
+			name:        "truncated",
+			mode:        config.DEVELOPER,
+			cfg:         &BodyCaptureConfig{MaxBodySize: 8},
+			path:        "/api",
+			reqBody:     `{"name":"ana"}`,
+			wantCapture: true,
+			wantReq:     `{"name":`,
+			wantReqTrc:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, buf := newBodyCaptureTestConf(tt.mode)
+
+			var handlerBody string
+			handler := BodyCaptureMiddleware(conf, tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				handlerBody = string(data)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"ok":true}`))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.reqBody)))
+
+			if handlerBody != tt.reqBody {
+				t.Errorf("handler body = %q, want %q", handlerBody, tt.reqBody)
+			}
+			if w.Code != http.StatusCreated || w.Body.String() != `{"ok":true}` {
+				t.Errorf("response = %d %s", w.Code, w.Body.String())
+			}
+
+			if !tt.wantCapture {
+				if buf.Len() != 0 {
+					t.Fatalf("unexpected capture: %s", buf.String())
+				}
+				return
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("log = %q: %v", buf.String(), err)
+			}
+			if entry["RequestBody"] != tt.wantReq {
+				t.Errorf("RequestBody = %v, want %s", entry["RequestBody"], tt.wantReq)
+			}
+			if entry["RequestBodyTruncated"] != tt.wantReqTrc {
+				t.Errorf("RequestBodyTruncated = %v, want %v", entry["RequestBodyTruncated"], tt.wantReqTrc)
+			}
+			if entry["StatusCode"] != float64(http.StatusCreated) {
+				t.Errorf("StatusCode = %v", entry["StatusCode"])
+			}
+		})
+	}
+}
+
+func TestBodyRedactor(t *testing.T) {
+	br := newBodyRedactor([]string{"password"})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "large integer", body: `{"id":9007199254740993,"password":"x"}`, want: `{"id":9007199254740993,"password":"` + LOG_REDACTED_VALUE + `"}`},
+		{name: "decimal", body: `{"amount":12345678901234567.89}`, want: `{"amount":12345678901234567.89}`},
+		{name: "nested array", body: `[{"id":18446744073709551615}]`, want: `[{"id":18446744073709551615}]`},
+		{name: "trailing data", body: `{"password":"x"} {"password":"y"}`, want: `{"password":"` + LOG_REDACTED_VALUE + `"} {"password":"` + LOG_REDACTED_VALUE + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := br.redact([]byte(tt.body), false); got != tt.want {
+				t.Errorf("redact(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyRedactorTruncated(t *testing.T) {
+	br := newBodyRedactor([]string{"password", "cpf"})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "string value", body: `{"password":"abc","name":"a`, want: `{"password":"` + LOG_REDACTED_VALUE + `","name":"a`},
+		{name: "cut string value", body: `{"name":"a","password":"ab`, want: `{"name":"a","password":"` + LOG_REDACTED_VALUE + `"`},
+		{name: "number value", body: `{"CPF": 123,"x`, want: `{"CPF": "` + LOG_REDACTED_VALUE + `","x`},
+		{name: "not json", body: `plain text`, want: `plain text`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := br.redact([]byte(tt.body), true); got != tt.want {
+				t.Errorf("redact(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	lb := &limitedBuffer{max: 4}
+	for _, chunk := range []string{"ab", "cd", "ef"} {
+		if n, err := lb.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%s) = %d, %v", chunk, n, err)
+		}
+	}
+	if lb.buf.String() != "abcd" || !lb.truncated {
+		t.Errorf("buf = %q, truncated = %v", lb.buf.String(), lb.truncated)
+	}
+}
@@ -0,0 +1,426 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+	"github.com/phuslu/log"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DEFAULT_CACHE_KEY_PREFIX = "httpcache:"
+	DEFAULT_CACHE_TTL        = 60 * time.Second
+)
+
+// CacheConfig configuração para o middleware de cache de respostas
+type CacheConfig struct {
+	// Redis cliente usado para guardar as respostas (obrigatório)
+	Redis redisdb.RedisClientInterface
+	// KeyPrefix prefixo das chaves no Redis (padrão: httpcache:)
+	KeyPrefix string
+	// TTL tempo padrão de cache, sobrescrito por max-age/s-maxage da resposta (padrão: 60s)
+	TTL time.Duration
+	// QueryParams parâmetros da query que fazem parte da chave. Vazio usa a query inteira.
+	QueryParams []string
+	// VaryHeaders headers da requisição que fazem parte da chave (ex: Accept-Language)
+	VaryHeaders []string
+	// Tags retorna as tags da resposta para uso em InvalidateTag
+	Tags func(r *http.Request) []string
+}
+
+type cachedResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header"`
+	Body   []byte              `json:"body"`
+	ETag   string              `json:"etag"`
+}
+
+// HttpCache cache de respostas GET guardado no Redis
+type HttpCache struct {
+	cfg   *CacheConfig
+	group singleflight.Group
+}
+
+// NewHttpCache cria o cache de respostas.
+//
+// Exemplo de Uso:
+//
+//	cache := httpserver.NewHttpCache(&httpserver.CacheConfig{
+//	    Redis:       redisConn,
+//	    TTL:         5 * time.Minute,
+//	    QueryParams: []string{"page", "limit"},
+//	    Tags: func(r *http.Request) []string { return []string{"products"} },
+//	})
+//	api.Use(cache.Middleware)
+//
+//	// depois de alterar um produto
+//	cache.InvalidateTag(ctx, "products")
+func NewHttpCache(cfg *CacheConfig) *HttpCache {
+	if cfg == nil || cfg.Redis == nil {
+		log.Fatal().Str("FunctionName", "NewHttpCache").Msg("CacheConfig.Redis é obrigatório!")
+	}
+
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = DEFAULT_CACHE_KEY_PREFIX
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DEFAULT_CACHE_TTL
+	}
+
+	return &HttpCache{cfg: cfg}
+}
+
+// Middleware aplica o cache nas requisições GET.
+//
+// Requisições com Cache-Control: no-store ignoram o cache; no-cache força a
+// atualização da entrada. Respostas com no-store, private ou Set-Cookie não são
+// guardadas. Requisições com Authorization ou Cookie só usam e guardam respostas
+// marcadas com public ou s-maxage (RFC 9111 §3.5). Apenas respostas 200 são
+// guardadas e todas recebem ETag, permitindo 304 quando o cliente envia
+// If-None-Match. Requisições simultâneas para a mesma chave executam o handler
+// apenas uma vez.
+func (c *HttpCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reqCC := parseCacheControl(r.Header.Get("Cache-Control"))
+		if _, ok := reqCC["no-store"]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := c.cacheKey(r)
+		credentialed := hasCredentials(r)
+
+		if _, noCache := reqCC["no-cache"]; !noCache {
+			if entry := c.load(r.Context(), key); entry != nil && (!credentialed || sharedWithCredentials(entry)) {
+				c.writeEntry(w, r, entry, "HIT")
+				return
+			}
+		}
+
+		// Respostas de requisições autenticadas não são compartilhadas pelo
+		// single-flight, cada usuário executa o handler
+		if credentialed {
+			c.writeEntry(w, r, c.fetch(next, r, key, true), "MISS")
+			return
+		}
+
+		result, _, _ := c.group.Do(key, func() (interface{}, error) {
+			return c.fetch(next, r, key, false), nil
+		})
+
+		c.writeEntry(w, r, result.(*cachedResponse), "MISS")
+	})
+}
+
+// fetch executa o handler e guarda a resposta quando ela pode ser cacheada
+func (c *HttpCache) fetch(next http.Handler, r *http.Request, key string, credentialed bool) *cachedResponse {
+	buf := newBufferedResponse()
+	next.ServeHTTP(buf, r)
+	if buf.status == 0 {
+		buf.status = http.StatusOK
+	}
+
+	entry := &cachedResponse{
+		Status: buf.status,
+		Header: buf.header,
+		Body:   buf.body.Bytes(),
+		ETag:   buf.header.Get("ETag"),
+	}
+
+	if entry.Status == http.StatusOK && entry.ETag == "" {
+		sum := sha256.Sum256(entry.Body)
+		entry.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+
+	if credentialed && !sharedWithCredentials(entry) {
+		return entry
+	}
+
+	if ttl, ok := c.storable(entry); ok {
+		c.store(r, key, entry, ttl)
+	}
+
+	return entry
+}
+
+// InvalidatePrefix remove todas as entradas cujo path começa com pathPrefix
+func (c *HttpCache) InvalidatePrefix(ctx context.Context, pathPrefix string) error {
+	rdb := c.cfg.Redis.GetClient()
+	match := c.cfg.KeyPrefix + http.MethodGet + ":" + escapeRedisPattern(pathPrefix) + "*"
+
+	iter := rdb.Scan(ctx, 0, match, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		log.Error().Str("FunctionName", "InvalidatePrefix").Str("ERRO_REDIS", "Erro ao tentar listar as chaves").Msg(err.Error())
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return rdb.Del(ctx, keys...).Err()
+}
+
+// InvalidateTag remove todas as entradas associadas à tag
+func (c *HttpCache) InvalidateTag(ctx context.Context, tag string) error {
+	rdb := c.cfg.Redis.GetClient()
+	tagKey := c.tagKey(tag)
+
+	keys, err := rdb.SMembers(ctx, tagKey).Result()
+	if err != nil {
+		log.Error().Str("FunctionName", "InvalidateTag").Str("ERRO_REDIS", "Erro ao tentar ler a tag").Msg(err.Error())
+		return err
+	}
+
+	return rdb.Del(ctx, append(keys, tagKey)...).Err()
+}
+
+func (c *HttpCache) cacheKey(r *http.Request) string {
+	var variant strings.Builder
+
+	query := r.URL.Query()
+	if len(c.cfg.QueryParams) > 0 {
+		selected := url.Values{}
+		for _, name := range c.cfg.QueryParams {
+			if values, ok := query[name]; ok {
+				selected[name] = values
+			}
+		}
+		query = selected
+	}
+	// Encode ordena os parâmetros, garantindo a mesma chave para a mesma query
+	variant.WriteString(query.Encode())
+
+	for _, name := range c.cfg.VaryHeaders {
+		variant.WriteString("|" + strings.ToLower(name) + "=" + r.Header.Get(name))
+	}
+
+	sum := sha256.Sum256([]byte(variant.String()))
+	return c.cfg.KeyPrefix + http.MethodGet + ":" + r.URL.Path + ":" + hex.EncodeToString(sum[:8])
+}
+
+func (c *HttpCache) tagKey(tag string) string {
+	return c.cfg.KeyPrefix + "tag:" + tag
+}
+
+func (c *HttpCache) load(ctx context.Context, key string) *cachedResponse {
+	data, err := c.cfg.Redis.GetClient().Get(ctx, key).Bytes()
+	if err != nil {
+		return nil
+	}
+
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Error().Str("FunctionName", "HttpCache.load").Str("Key", key).Msg(err.Error())
+		return nil
+	}
+
+	return &entry
+}
+
+func (c *HttpCache) storable(entry *cachedResponse) (time.Duration, bool) {
+	if entry.Status != http.StatusOK {
+		return 0, false
+	}
+
+	if len(entry.Header["Set-Cookie"]) > 0 {
+		return 0, false
+	}
+
+	respCC := parseCacheControl(http.Header(entry.Header).Get("Cache-Control"))
+	if _, ok := respCC["no-store"]; ok {
+		return 0, false
+	}
+	if _, ok := respCC["private"]; ok {
+		return 0, false
+	}
+
+	ttl := c.cfg.TTL
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if v, ok := respCC[directive]; ok {
+			seconds, err := strconv.Atoi(v)
+			if err != nil {
+				continue
+			}
+			if seconds <= 0 {
+				return 0, false
+			}
+			ttl = time.Duration(seconds) * time.Second
+			break
+		}
+	}
+
+	return ttl, true
+}
+
+func (c *HttpCache) store(r *http.Request, key string, entry *cachedResponse, ttl time.Duration) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Error().Str("FunctionName", "HttpCache.store").Msg(err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if ok := c.cfg.Redis.SaveData(ctx, key, data, ttl); !ok {
+		log.Error().Str("FunctionName", "HttpCache.store").Str("ERRO_REDIS", "Erro ao tentar salvar a resposta").Str("Key", key).Msg("SaveData failed")
+		return
+	}
+
+	if c.cfg.Tags == nil {
+		return
+	}
+
+	rdb := c.cfg.Redis.GetClient()
+	for _, tag := range c.cfg.Tags(r) {
+		tagKey := c.tagKey(tag)
+		rdb.SAdd(ctx, tagKey, key)
+		// A tag vive pelo menos tanto quanto a maior entrada associada
+		if current := rdb.TTL(ctx, tagKey).Val(); current < ttl {
+			rdb.Expire(ctx, tagKey, ttl)
+		}
+	}
+}
+
+func (c *HttpCache) writeEntry(w http.ResponseWriter, r *http.Request, entry *cachedResponse, cacheStatus string) {
+	for k, values := range entry.Header {
+		w.Header()[k] = append([]string(nil), values...)
+	}
+
+	if entry.ETag != "" {
+		w.Header().Set("ETag", entry.ETag)
+	}
+	if len(c.cfg.VaryHeaders) > 0 {
+		w.Header().Set("Vary", mergeVary(w.Header().Values("Vary"), c.cfg.VaryHeaders))
+	}
+	w.Header().Set("X-Cache", cacheStatus)
+
+	if entry.ETag != "" && etagMatches(r.Header.Get("If-None-Match"), entry.ETag) {
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(entry.Status)
+	w.Write(entry.Body)
+}
+
+// mergeVary junta o Vary definido pelo handler com os VaryHeaders da configuração,
+// sem repetir nomes
+func mergeVary(current []string, extra []string) string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if key := http.CanonicalHeaderKey(name); name != "" && !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, value := range current {
+		for _, name := range strings.Split(value, ",") {
+			add(name)
+		}
+	}
+	for _, name := range extra {
+		add(name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// hasCredentials indica se a resposta pode ser específica do usuário (RFC 9111 §3.5)
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// sharedWithCredentials indica se a resposta pode ser compartilhada mesmo com
+// Authorization ou Cookie na requisição: precisa de public ou s-maxage
+func sharedWithCredentials(entry *cachedResponse) bool {
+	respCC := parseCacheControl(http.Header(entry.Header).Get("Cache-Control"))
+	_, public := respCC["public"]
+	_, sMaxAge := respCC["s-maxage"]
+	return public || sMaxAge
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseCacheControl(header string) map[string]string {
+	directives := map[string]string{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
+
+func escapeRedisPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(s)
+}
+
+// bufferedResponse guarda a resposta do handler em memória, sem enviar ao cliente
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: http.Header{}}
+}
+
+func (br *bufferedResponse) Header() http.Header {
+	return br.header
+}
+
+func (br *bufferedResponse) WriteHeader(status int) {
+	if br.status == 0 {
+		br.status = status
+	}
+}
+
+func (br *bufferedResponse) Write(b []byte) (int, error) {
+	if br.status == 0 {
+		br.status = http.StatusOK
+	}
+	return br.body.Write(b)
+}
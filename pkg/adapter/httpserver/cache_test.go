@@ -0,0 +1,225 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHttpCacheMiddleware(t *testing.T) {
+	rdb, _ := newTestRedis(t)
+
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/shared":
+			w.Header().Set("Cache-Control", "s-maxage=60")
+		case "/cookie":
+			w.Header().Set("Set-Cookie", "a=b")
+		case "/error":
+			w.WriteHeader(http.StatusNotFound)
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/vary":
+			w.Header().Add("Vary", "Accept-Encoding")
+		}
+		w.Write([]byte(r.Header.Get("Authorization") + "#" + strconv.Itoa(int(n))))
+	})
+
+	cache := NewHttpCache(&CacheConfig{
+		Redis:       rdb,
+		KeyPrefix:   "test:",
+		QueryParams: []string{"page"},
+		VaryHeaders: []string{"Accept-Language"},
+		Tags:        func(r *http.Request) []string { return []string{"all"} },
+	})
+	srv := cache.Middleware(handler)
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		header    []string
+		wantCache string
+		wantCalls int32
+	}{
+		{name: "first miss", path: "/items?page=1", wantCache: "MISS", wantCalls: 1},
+		{name: "hit", path: "/items?page=1", wantCache: "HIT"},
+		{name: "ignored query param", path: "/items?page=1&utm=x", wantCache: "HIT"},
+		{name: "selected query param", path: "/items?page=2", wantCache: "MISS", wantCalls: 1},
+		{name: "vary header", path: "/items?page=1", header: []string{"Accept-Language", "en"}, wantCache: "MISS", wantCalls: 1},
+		{name: "no-cache refreshes", path: "/items?page=1", header: []string{"Cache-Control", "no-cache"}, wantCache: "MISS", wantCalls: 1},
+		{name: "no-store bypasses", path: "/items?page=1", header: []string{"Cache-Control", "no-store"}, wantCalls: 1},
+		{name: "authorization skips lookup", path: "/items?page=1", header: []string{"Authorization", "Bearer a"}, wantCache: "MISS", wantCalls: 1},
+		{name: "authorization not stored", path: "/items?page=1", header: []string{"Authorization", "Bearer b"}, wantCache: "MISS", wantCalls: 1},
+		{name: "cookie skips lookup", path: "/items?page=1", header: []string{"Cookie", "session=1"}, wantCache: "MISS", wantCalls: 1},
+		{name: "private miss", path: "/private", wantCache: "MISS", wantCalls: 1},
+		{name: "private not stored", path: "/private", wantCache: "MISS", wantCalls: 1},
+		{name: "set-cookie not stored", path: "/cookie", wantCache: "MISS", wantCalls: 1},
+		{name: "set-cookie not stored again", path: "/cookie", wantCache: "MISS", wantCalls: 1},
+		{name: "404 not stored", path: "/error", wantCache: "MISS", wantCalls: 1},
+		{name: "404 not stored again", path: "/error", wantCache: "MISS", wantCalls: 1},
+		{name: "public with authorization stored", path: "/public", header: []string{"Authorization", "Bearer a"}, wantCache: "MISS", wantCalls: 1},
+		{name: "public with authorization hit", path: "/public", header: []string{"Authorization", "Bearer b"}, wantCache: "HIT"},
+		{name: "s-maxage with cookie stored", path: "/shared", header: []string{"Cookie", "a=1"}, wantCache: "MISS", wantCalls: 1},
+		{name: "s-maxage with cookie hit", path: "/shared", header: []string{"Cookie", "a=2"}, wantCache: "HIT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := calls.Load()
+			w := get(tt.path, tt.header...)
+			if got := w.Header().Get("X-Cache"); got != tt.wantCache {
+				t.Errorf("X-Cache = %q, want %q", got, tt.wantCache)
+			}
+			if got := calls.Load() - before; got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("authenticated response is not served to others", func(t *testing.T) {
+		get("/me", "Authorization", "Bearer alice")
+		w := get("/me", "Authorization", "Bearer bob")
+		if body := w.Body.String(); body[:len("Bearer bob")] != "Bearer bob" {
+			t.Fatalf("bob received %q", body)
+		}
+		if w := get("/me"); w.Body.String()[0] != '#' {
+			t.Fatalf("anonymous received %q", w.Body.String())
+		}
+	})
+
+	t.Run("etag 304", func(t *testing.T) {
+		first := get("/etag")
+		etag := first.Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("missing ETag")
+		}
+		if w := get("/etag", "If-None-Match", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Fatalf("status = %d, body = %q", w.Code, w.Body.String())
+		}
+		if w := get("/etag", "If-None-Match", `"other"`); w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+	})
+
+	t.Run("vary merged with handler", func(t *testing.T) {
+		for _, want := range []string{"MISS", "HIT"} {
+			w := get("/vary")
+			if w.Header().Get("X-Cache") != want {
+				t.Fatalf("X-Cache = %q, want %q", w.Header().Get("X-Cache"), want)
+			}
+			if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding, Accept-Language" {
+				t.Fatalf("%s Vary = %q", want, got)
+			}
+		}
+	})
+
+	t.Run("single flight", func(t *testing.T) {
+		before := calls.Load()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				get("/slow")
+			}()
+		}
+		wg.Wait()
+		if got := calls.Load() - before; got != 1 {
+			t.Fatalf("calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("invalidate prefix", func(t *testing.T) {
+		if err := cache.InvalidatePrefix(context.Background(), "/items"); err != nil {
+			t.Fatal(err)
+		}
+		if w := get("/items?page=1"); w.Header().Get("X-Cache") != "MISS" {
+			t.Fatalf("X-Cache = %q, want MISS", w.Header().Get("X-Cache"))
+		}
+		if w := get("/etag"); w.Header().Get("X-Cache") != "HIT" {
+			t.Fatalf("other prefix invalidated")
+		}
+	})
+
+	t.Run("invalidate tag", func(t *testing.T) {
+		if err := cache.InvalidateTag(context.Background(), "all"); err != nil {
+			t.Fatal(err)
+		}
+		if w := get("/etag"); w.Header().Get("X-Cache") != "MISS" {
+			t.Fatalf("X-Cache = %q, want MISS", w.Header().Get("X-Cache"))
+		}
+	})
+
+	t.Run("post passes through", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/items?page=1", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		if w.Header().Get("X-Cache") != "" {
+			t.Fatal("POST was cached")
+		}
+	})
+}
+
+func TestMergeVary(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []string
+		extra   []string
+		want    string
+	}{
+		{name: "only config", extra: []string{"Accept-Language"}, want: "Accept-Language"},
+		{name: "handler first", current: []string{"Accept-Encoding"}, extra: []string{"Accept-Language"}, want: "Accept-Encoding, Accept-Language"},
+		{name: "comma separated", current: []string{"Origin, Accept-Encoding", "X-Tenant"}, extra: []string{"Accept-Language"}, want: "Origin, Accept-Encoding, X-Tenant, Accept-Language"},
+		{name: "duplicates ignore case", current: []string{"accept-language"}, extra: []string{"Accept-Language"}, want: "accept-language"},
+		{name: "empty values", current: []string{" , "}, extra: []string{"Accept-Language"}, want: "Accept-Language"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeVary(tt.current, tt.extra); got != tt.want {
+				t.Errorf("mergeVary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header, etag string
+		want         bool
+	}{
+		{"", `"a"`, false},
+		{`"a"`, `"a"`, true},
+		{`W/"a"`, `"a"`, true},
+		{`"b", "a"`, `"a"`, true},
+		{`*`, `"a"`, true},
+		{`"b"`, `"a"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, tt.etag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v", tt.header, tt.etag, got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/phuslu/log"
+	"github.com/rs/cors"
+)
+
+const DEFAULT_CORS_MAX_AGE = 600 // 10 minutos
+
+// DefaultCORSMethods métodos usados quando CORS_METHODS está vazio
+var DefaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// DefaultCORSHeaders headers usados quando CORS_HEADERS está vazio
+var DefaultCORSHeaders = []string{"Accept", "Authorization", "Content-Type", "Idempotency-Key", "X-Request-Id"}
+
+// DynamicCORS aplica o CORS configurado no HttpConfig e permite trocar as
+// opções em tempo de execução com Reload, sem reiniciar o servidor.
+type DynamicCORS struct {
+	current atomic.Pointer[cors.Cors]
+	mu      sync.Mutex
+	opts    cors.Options
+}
+
+var (
+	serverCORSMu sync.Mutex
+	serverCORS   *DynamicCORS
+)
+
+// NewDynamicCORS lê as variáveis SRV_HTTP_CORS_* e valida a configuração.
+//
+// Variáveis de ambiente (listas separadas por vírgula):
+//
+//	SRV_HTTP_CORS_ORIGINS=https://app.example.com,https://*.example.com
+//	SRV_HTTP_CORS_METHODS=GET,POST
+//	SRV_HTTP_CORS_HEADERS=Authorization,Content-Type
+//	SRV_HTTP_CORS_CREDENTIALS=true
+//	SRV_HTTP_CORS_MAX_AGE=600
+//
+// As origens aceitam "*" (todas) ou scheme://host[:porta], onde o host pode
+// começar com "*." para liberar todos os subdomínios.
+//
+// As variáveis são lidas apenas aqui; depois disso use Reload com o HttpConfig
+// atualizado.
+func NewDynamicCORS(conf *config.Config) (*DynamicCORS, error) {
+	loadCORSConfig(conf)
+
+	dc := &DynamicCORS{}
+	if err := dc.Reload(conf.HttpConfig); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// Reload valida os campos CORS_* do HttpConfig informado e troca as opções em
+// uso. As variáveis de ambiente não são lidas novamente, então alterações
+// feitas no HttpConfig pelo código são mantidas. Se a nova configuração for
+// inválida as opções anteriores são mantidas.
+func (dc *DynamicCORS) Reload(cfg *config.HttpConfig) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	opts, err := corsOptionsFromConfig(cfg)
+	if err != nil {
+		log.Error().Str("FunctionName", "DynamicCORS.Reload").Msg(err.Error())
+		return err
+	}
+
+	dc.opts = opts
+	dc.current.Store(cors.New(opts))
+
+	log.Info().Strs("CorsOrigins", opts.AllowedOrigins).Bool("CorsCredentials", opts.AllowCredentials).Msg("CORS configuration loaded")
+
+	return nil
+}
+
+// Options retorna uma cópia das opções em uso
+func (dc *DynamicCORS) Options() cors.Options {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc.opts
+}
+
+// Handler aplica o CORS em uso no momento de cada requisição
+func (dc *DynamicCORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dc.current.Load().Handler(next).ServeHTTP(w, r)
+	})
+}
+
+// ReloadCORS aplica os campos CORS_* do HttpConfig informado no servidor
+// criado pelo httpserver.New. Retorna erro se o servidor não usa o CORS do
+// HttpConfig (cors.Options informado no código) ou se a configuração for inválida.
+//
+// As variáveis de ambiente de um processo não mudam depois que ele inicia, por
+// isso os novos valores devem vir de outra fonte, como um arquivo de configuração.
+//
+// Exemplo de Uso:
+//
+//	sighup := make(chan os.Signal, 1)
+//	signal.Notify(sighup, syscall.SIGHUP)
+//	go func() {
+//	    for range sighup {
+//	        origins, err := loadOriginsFromFile("/etc/app/cors-origins")
+//	        if err != nil {
+//	            continue
+//	        }
+//	        conf.CORS_ORIGINS = origins
+//	        httpserver.ReloadCORS(conf.HttpConfig)
+//	    }
+//	}()
+func ReloadCORS(cfg *config.HttpConfig) error {
+	serverCORSMu.Lock()
+	dc := serverCORS
+	serverCORSMu.Unlock()
+
+	if dc == nil {
+		return fmt.Errorf("cors from HttpConfig is not enabled")
+	}
+
+	return dc.Reload(cfg)
+}
+
+func loadCORSConfig(conf *config.Config) {
+	SRV_HTTP_CORS_ORIGINS := os.Getenv("SRV_HTTP_CORS_ORIGINS")
+	if SRV_HTTP_CORS_ORIGINS != "" {
+		conf.CORS_ORIGINS = splitEnvList(SRV_HTTP_CORS_ORIGINS)
+	}
+
+	SRV_HTTP_CORS_METHODS := os.Getenv("SRV_HTTP_CORS_METHODS")
+	if SRV_HTTP_CORS_METHODS != "" {
+		conf.CORS_METHODS = splitEnvList(SRV_HTTP_CORS_METHODS)
+	}
+
+	SRV_HTTP_CORS_HEADERS := os.Getenv("SRV_HTTP_CORS_HEADERS")
+	if SRV_HTTP_CORS_HEADERS != "" {
+		conf.CORS_HEADERS = splitEnvList(SRV_HTTP_CORS_HEADERS)
+	}
+
+	SRV_HTTP_CORS_CREDENTIALS := os.Getenv("SRV_HTTP_CORS_CREDENTIALS")
+	if SRV_HTTP_CORS_CREDENTIALS != "" {
+		credentials, err := strconv.ParseBool(SRV_HTTP_CORS_CREDENTIALS)
+		if err != nil {
+			log.Error().Str("SRV_HTTP_CORS_CREDENTIALS", "Invalid value").Str("SetDefaultValue", "false").Msg(err.Error())
+		}
+		conf.CORS_CREDENTIALS = credentials
+	}
+
+	SRV_HTTP_CORS_MAX_AGE := os.Getenv("SRV_HTTP_CORS_MAX_AGE")
+	if SRV_HTTP_CORS_MAX_AGE != "" {
+		maxAge, err := strconv.Atoi(SRV_HTTP_CORS_MAX_AGE)
+		if err != nil {
+			log.Error().Str("SRV_HTTP_CORS_MAX_AGE", "Invalid value").Str("SetDefaultValue", "10 minutes").Msg(err.Error())
+			maxAge = DEFAULT_CORS_MAX_AGE
+		}
+		conf.CORS_MAX_AGE = maxAge
+	}
+}
+
+func corsOptionsFromConfig(cfg *config.HttpConfig) (cors.Options, error) {
+	if len(cfg.CORS_ORIGINS) == 0 {
+		return cors.Options{}, fmt.Errorf("cors: no origin configured, set SRV_HTTP_CORS_ORIGINS")
+	}
+
+	for _, origin := range cfg.CORS_ORIGINS {
+		if err := validateCORSOrigin(origin); err != nil {
+			return cors.Options{}, err
+		}
+		if origin == "*" && cfg.CORS_CREDENTIALS {
+			return cors.Options{}, fmt.Errorf("cors: origin \"*\" cannot be used with credentials")
+		}
+	}
+
+	configured := cfg.CORS_METHODS
+	if len(configured) == 0 {
+		configured = DefaultCORSMethods
+	}
+	methods := make([]string, 0, len(configured))
+	for _, method := range configured {
+		if method == "" || strings.ContainsAny(method, " \t/") {
+			return cors.Options{}, fmt.Errorf("cors: invalid method %q", method)
+		}
+		methods = append(methods, strings.ToUpper(method))
+	}
+
+	headers := cfg.CORS_HEADERS
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+
+	maxAge := cfg.CORS_MAX_AGE
+	if maxAge == 0 {
+		maxAge = DEFAULT_CORS_MAX_AGE
+	}
+	if maxAge < 0 {
+		return cors.Options{}, fmt.Errorf("cors: invalid max age %d", maxAge)
+	}
+
+	return cors.Options{
+		AllowedOrigins:   cfg.CORS_ORIGINS,
+		AllowedMethods:   methods,
+		AllowedHeaders:   headers,
+		AllowCredentials: cfg.CORS_CREDENTIALS,
+		MaxAge:           maxAge,
+	}, nil
+}
+
+// validateCORSOrigin aceita "*" ou scheme://host[:porta], com o host podendo
+// começar com "*." para liberar os subdomínios
+func validateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	u, err := url.Parse(strings.Replace(origin, "*.", "wildcard.", 1))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("cors: invalid origin %q, expected scheme://host[:port]", origin)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("cors: invalid origin %q, scheme must be http or https", origin)
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("cors: invalid origin %q, only scheme, host and port are allowed", origin)
+	}
+	if strings.Contains(origin, "*") && !strings.HasPrefix(origin, u.Scheme+"://*.") {
+		return fmt.Errorf("cors: invalid origin %q, the wildcard must be the first label of the host (ex: https://*.example.com)", origin)
+	}
+	if strings.Count(origin, "*") > 1 {
+		return fmt.Errorf("cors: invalid origin %q, only one wildcard is allowed", origin)
+	}
+
+	return nil
+}
+
+func splitEnvList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,154 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+)
+
+func TestValidateCORSOrigin(t *testing.T) {
+	tests := []struct {
+		origin  string
+		wantErr bool
+	}{
+		{origin: "*"},
+		{origin: "https://app.example.com"},
+		{origin: "http://localhost:3000"},
+		{origin: "https://*.example.com"},
+		{origin: "app.example.com", wantErr: true},
+		{origin: "ftp://example.com", wantErr: true},
+		{origin: "https://example.com/path", wantErr: true},
+		{origin: "https://example.com?x=1", wantErr: true},
+		{origin: "https://app.*.example.com", wantErr: true},
+		{origin: "https://*.*.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if err := validateCORSOrigin(tt.origin); (err != nil) != tt.wantErr {
+				t.Errorf("validateCORSOrigin(%q) = %v, wantErr %v", tt.origin, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCORSOptionsFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.HttpConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: &config.HttpConfig{CORS_ORIGINS: []string{"https://a.com"}}},
+		{name: "no origin", cfg: &config.HttpConfig{}, wantErr: true},
+		{name: "invalid origin", cfg: &config.HttpConfig{CORS_ORIGINS: []string{"a.com"}}, wantErr: true},
+		{name: "wildcard with credentials", cfg: &config.HttpConfig{CORS_ORIGINS: []string{"*"}, CORS_CREDENTIALS: true}, wantErr: true},
+		{name: "invalid method", cfg: &config.HttpConfig{CORS_ORIGINS: []string{"*"}, CORS_METHODS: []string{"GE T"}}, wantErr: true},
+		{name: "negative max age", cfg: &config.HttpConfig{CORS_ORIGINS: []string{"*"}, CORS_MAX_AGE: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := corsOptionsFromConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (opts.MaxAge != DEFAULT_CORS_MAX_AGE || len(opts.AllowedMethods) != len(DefaultCORSMethods)) {
+				t.Errorf("opts = %+v", opts)
+			}
+		})
+	}
+}
+
+func corsPreflight(h http.Handler, origin string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestDynamicCORSReload(t *testing.T) {
+	t.Setenv("SRV_HTTP_CORS_ORIGINS", "https://a.com")
+	t.Setenv("SRV_HTTP_CORS_CREDENTIALS", "")
+	t.Setenv("SRV_HTTP_CORS_METHODS", "")
+	t.Setenv("SRV_HTTP_CORS_HEADERS", "")
+	t.Setenv("SRV_HTTP_CORS_MAX_AGE", "")
+
+	conf := &config.Config{HttpConfig: &config.HttpConfig{}}
+	dc, err := NewDynamicCORS(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := dc.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	allowed := func(origin string) bool {
+		return corsPreflight(h, origin).Header().Get("Access-Control-Allow-Origin") == origin
+	}
+
+	if !allowed("https://a.com") || allowed("https://b.com") {
+		t.Fatal("initial origins not applied")
+	}
+
+	// Alteração feita pelo código não pode ser sobrescrita pelo ambiente
+	conf.CORS_ORIGINS = []string{"https://b.com"}
+	if err := dc.Reload(conf.HttpConfig); err != nil {
+		t.Fatal(err)
+	}
+	if allowed("https://a.com") || !allowed("https://b.com") {
+		t.Fatalf("reload not applied: %v", dc.Options().AllowedOrigins)
+	}
+
+	// Configuração inválida mantém as opções anteriores
+	if err := dc.Reload(&config.HttpConfig{CORS_ORIGINS: []string{"invalid"}}); err == nil {
+		t.Fatal("expected error")
+	}
+	if !allowed("https://b.com") {
+		t.Fatal("previous options lost after invalid reload")
+	}
+}
+
+func TestReloadCORSWithoutServer(t *testing.T) {
+	serverCORSMu.Lock()
+	prev := serverCORS
+	serverCORS = nil
+	serverCORSMu.Unlock()
+	defer func() {
+		serverCORSMu.Lock()
+		serverCORS = prev
+		serverCORSMu.Unlock()
+	}()
+
+	if err := ReloadCORS(&config.HttpConfig{CORS_ORIGINS: []string{"*"}}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestLoadCORSConfig(t *testing.T) {
+	t.Setenv("SRV_HTTP_CORS_ORIGINS", " https://a.com , https://b.com ,")
+	t.Setenv("SRV_HTTP_CORS_METHODS", "GET,POST")
+	t.Setenv("SRV_HTTP_CORS_HEADERS", "")
+	t.Setenv("SRV_HTTP_CORS_CREDENTIALS", "true")
+	t.Setenv("SRV_HTTP_CORS_MAX_AGE", "x")
+
+	conf := &config.Config{HttpConfig: &config.HttpConfig{CORS_HEADERS: []string{"X-Keep"}}}
+	loadCORSConfig(conf)
+
+	if len(conf.CORS_ORIGINS) != 2 || conf.CORS_ORIGINS[1] != "https://b.com" {
+		t.Errorf("origins = %v", conf.CORS_ORIGINS)
+	}
+	if len(conf.CORS_METHODS) != 2 {
+		t.Errorf("methods = %v", conf.CORS_METHODS)
+	}
+	if len(conf.CORS_HEADERS) != 1 || conf.CORS_HEADERS[0] != "X-Keep" {
+		t.Errorf("headers = %v", conf.CORS_HEADERS)
+	}
+	if !conf.CORS_CREDENTIALS {
+		t.Error("credentials not set")
+	}
+	if conf.CORS_MAX_AGE != DEFAULT_CORS_MAX_AGE {
+		t.Errorf("max age = %d", conf.CORS_MAX_AGE)
+	}
+}
@@ -0,0 +1,211 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+	"github.com/phuslu/log"
+)
+
+const DEFAULT_MAX_BODY_SIZE = 1 << 20 // 1 MiB
+
+// DecodeLimits configuração usada pelo DecodeJSON
+type DecodeLimits struct {
+	// MaxBodySize tamanho máximo do corpo em bytes (padrão: DEFAULT_MAX_BODY_SIZE)
+	MaxBodySize int64
+	// DisallowUnknownFields rejeita campos que não existem na struct de destino
+	DisallowUnknownFields bool
+}
+
+var ErroHttpMsgRequestBodyEmpty HttpMsg = HttpMsg{
+	Msg:  "Erro Request Body Empty",
+	Code: http.StatusBadRequest,
+}
+
+var ErroHttpMsgInternalServerError HttpMsg = HttpMsg{
+	Msg:  "Erro Internal Server Error",
+	Code: http.StatusInternalServerError,
+}
+
+var ErroHttpMsgValidationFailed HttpMsg = HttpMsg{
+	Msg:  "Erro Validation Failed",
+	Code: http.StatusUnprocessableEntity,
+}
+
+var ErroHttpMsgRequestBodyTooLarge HttpMsg = HttpMsg{
+	Msg:  "Erro Request Body Too Large",
+	Code: http.StatusRequestEntityTooLarge,
+}
+
+// DecodeJSON lê o corpo da requisição respeitando o limite de tamanho e
+// faz o decode para T. Em caso de falha o erro retornado é sempre um *HttpMsg
+// pronto para ser escrito na resposta com Write.
+//
+// Exemplo de Uso:
+//
+//	user, err := httpserver.DecodeJSON[User](r, nil)
+//	if err != nil {
+//	    httpserver.WriteError(w, err)
+//	    return
+//	}
+func DecodeJSON[T any](r *http.Request, limits *DecodeLimits) (T, error) {
+	var v T
+	err := decodeJSONInto(r, limits, &v)
+	return v, err
+}
+
+func decodeJSONInto(r *http.Request, limits *DecodeLimits, dst interface{}) error {
+	if limits == nil {
+		limits = &DecodeLimits{}
+	}
+
+	maxBodySize := limits.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DEFAULT_MAX_BODY_SIZE
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		msg := ErroHttpMsgRequestBodyEmpty
+		return &msg
+	}
+
+	body := http.MaxBytesReader(nil, r.Body, maxBodySize)
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	if limits.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeErrorToHttpMsg(err)
+	}
+
+	// O corpo deve conter um único valor JSON
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			return &HttpMsg{
+				Msg:  "Erro Request Body Must Contain A Single JSON Value",
+				Code: http.StatusBadRequest,
+			}
+		}
+		return decodeErrorToHttpMsg(err)
+	}
+
+	return nil
+}
+
+// DecodeAndValidate faz o DecodeJSON e em seguida valida o resultado com as
+// tags `validate` (ver pacote validator). Campos inválidos são retornados
+// todos juntos em um *HttpMsg com status 422 e a lista em Errors.
+//
+// Exemplo de Uso:
+//
+//	user, err := httpserver.DecodeAndValidate[User](r, nil)
+//	if err != nil {
+//	    httpserver.WriteError(w, err)
+//	    return
+//	}
+func DecodeAndValidate[T any](r *http.Request, limits *DecodeLimits) (T, error) {
+	v, err := DecodeJSON[T](r, limits)
+	if err != nil {
+		return v, err
+	}
+
+	return v, validateDecoded(&v)
+}
+
+// validateDecoded converte as ValidationErrors em um *HttpMsg 422
+func validateDecoded(v interface{}) error {
+	if err := validator.Validate(v); err != nil {
+		var verrs validator.ValidationErrors
+		if errors.As(err, &verrs) {
+			msg := ErroHttpMsgValidationFailed
+			msg.Errors = verrs
+			return &msg
+		}
+		return err
+	}
+
+	return nil
+}
+
+func decodeErrorToHttpMsg(err error) *HttpMsg {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		msg := ErroHttpMsgRequestBodyTooLarge
+		msg.Msg = fmt.Sprintf("%s (limit %d bytes)", msg.Msg, maxBytesErr.Limit)
+		return &msg
+	case errors.Is(err, io.EOF):
+		msg := ErroHttpMsgRequestBodyEmpty
+		return &msg
+	case errors.As(err, &syntaxErr):
+		return &HttpMsg{
+			Msg:  fmt.Sprintf("Erro Invalid JSON Syntax (at position %d)", syntaxErr.Offset),
+			Code: http.StatusBadRequest,
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &HttpMsg{
+			Msg:  "Erro Invalid JSON Syntax (unexpected end of body)",
+			Code: http.StatusBadRequest,
+		}
+	case errors.As(err, &typeErr):
+		return &HttpMsg{
+			Msg:   fmt.Sprintf("Erro Invalid Type (expected %s but got %s)", typeErr.Type, typeErr.Value),
+			Code:  http.StatusBadRequest,
+			Field: typeErr.Field,
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return &HttpMsg{
+			Msg:   "Erro Unknown Field",
+			Code:  http.StatusBadRequest,
+			Field: strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`),
+		}
+	default:
+		log.Error().Str("FunctionName", "DecodeJSON").Msg(err.Error())
+		return &HttpMsg{
+			Msg:  "Erro Invalid Request Body",
+			Code: http.StatusBadRequest,
+		}
+	}
+}
+
+// WriteJSON escreve v como JSON com o mesmo Content-Type do ContentTypeJSONMiddleware
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Error().Str("FunctionName", "WriteJSON").Msg(err.Error())
+		w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+		ErroHttpMsgInternalServerError.Write(w)
+		return err
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteError escreve o erro na resposta. Erros do tipo *HttpMsg mantêm o
+// código e a mensagem, os demais viram 500 sem expor detalhes internos.
+func WriteError(w http.ResponseWriter, err error) {
+	var msg *HttpMsg
+	if errors.As(err, &msg) {
+		w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+		msg.Write(w)
+		return
+	}
+
+	log.Error().Str("FunctionName", "WriteError").Msg(err.Error())
+	w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+	ErroHttpMsgInternalServerError.Write(w)
+}
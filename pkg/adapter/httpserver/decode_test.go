@@ -0,0 +1,150 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodeTestUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		limits    *DecodeLimits
+		wantCode  int
+		wantField string
+		wantMsg   string
+	}{
+		{name: "valid", body: `{"name":"ana","age":30}`},
+		{name: "empty", body: "", wantCode: http.StatusBadRequest, wantMsg: "Erro Request Body Empty"},
+		{name: "syntax", body: `{"name":}`, wantCode: http.StatusBadRequest, wantMsg: "Erro Invalid JSON Syntax"},
+		{name: "truncated", body: `{"name":"ana"`, wantCode: http.StatusBadRequest, wantMsg: "Erro Invalid JSON Syntax"},
+		{name: "type", body: `{"age":"x"}`, wantCode: http.StatusBadRequest, wantField: "age", wantMsg: "Erro Invalid Type"},
+		{name: "unknown allowed", body: `{"name":"ana","extra":1}`},
+		{
+			name:      "unknown rejected",
+			body:      `{"name":"ana","extra":1}`,
+			limits:    &DecodeLimits{DisallowUnknownFields: true},
+			wantCode:  http.StatusBadRequest,
+			wantField: "extra",
+			wantMsg:   "Erro Unknown Field",
+		},
+		{name: "multiple values", body: `{"name":"a"}{"name":"b"}`, wantCode: http.StatusBadRequest, wantMsg: "Erro Request Body Must Contain A Single JSON Value"},
+		{
+			name:     "too large",
+			body:     `{"name":"` + strings.Repeat("a", 100) + `"}`,
+			limits:   &DecodeLimits{MaxBodySize: 16},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantMsg:  "Erro Request Body Too Large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.body == "" {
+				r.Body = http.NoBody
+			}
+
+			user, err := DecodeJSON[decodeTestUser](r, tt.limits)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if user.Name != "ana" {
+					t.Fatalf("name = %q, want ana", user.Name)
+				}
+				return
+			}
+
+			var msg *HttpMsg
+			if !errors.As(err, &msg) {
+				t.Fatalf("error = %v, want *HttpMsg", err)
+			}
+			if msg.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", msg.Code, tt.wantCode)
+			}
+			if msg.Field != tt.wantField {
+				t.Errorf("field = %q, want %q", msg.Field, tt.wantField)
+			}
+			if !strings.HasPrefix(msg.Msg, tt.wantMsg) {
+				t.Errorf("msg = %q, want prefix %q", msg.Msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := WriteJSON(w, http.StatusCreated, decodeTestUser{Name: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != CONTENT_TYPE_JSON {
+		t.Errorf("content-type = %q", ct)
+	}
+	if body := w.Body.String(); body != `{"name":"ana","age":0}` {
+		t.Errorf("body = %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	if err := WriteJSON(w, http.StatusOK, make(chan int)); err == nil {
+		t.Fatal("expected marshal error")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	w := httptest.NewRecorder()
+	msg := ErroHttpMsgRequestBodyTooLarge
+	WriteError(w, &msg)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	WriteError(w, errors.New("db down"))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "db down") {
+		t.Errorf("internal error leaked: %s", w.Body.String())
+	}
+}
+
+func TestDecodeAndValidate(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" validate:"required"`
+		Age  int    `json:"age" validate:"min=18"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age":0}`))
+	_, err := DecodeAndValidate[payload](r, nil)
+
+	var msg *HttpMsg
+	if !errors.As(err, &msg) {
+		t.Fatalf("error = %v, want *HttpMsg", err)
+	}
+	if msg.Code != http.StatusUnprocessableEntity {
+		t.Errorf("code = %d, want 422", msg.Code)
+	}
+	if len(msg.Errors) != 2 {
+		t.Errorf("errors = %v, want name and age", msg.Errors)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"ana","age":20}`))
+	if _, err := DecodeAndValidate[payload](r, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
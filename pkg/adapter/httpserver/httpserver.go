@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/faelp22/go-commons-libs/pkg/validator"
 	"github.com/gorilla/mux"
 	"github.com/phuslu/log"
 	"github.com/rs/cors"
@@ -18,11 +20,26 @@ import (
 
 // LoggingMiddlewareConfig configuração para o middleware de logging
 type LoggingMiddlewareConfig struct {
-	// IgnorePaths lista de prefixos de paths que NÃO devem gerar logs
-	// Exemplo: []string{"/assets/", "/static/", "/favicon.ico"}
+	// IgnorePaths lista de prefixos ou globs de paths que NÃO devem gerar logs
+	// Exemplo: []string{"/assets/", "/static/", "/favicon.ico", "/api/*/metrics"}
 	IgnorePaths []string
 	// Enabled habilita ou desabilita completamente o logging (padrão: true)
 	Enabled bool
+	// Filters filtros por path e status, ex: só logar 5xx do /health
+	// Exemplo: []LogPathFilter{{Path: "/health", LogStatus: []string{"5xx"}}}
+	Filters []LogPathFilter
+	// Fields campos incluídos no log JSON (padrão: DefaultLogFields)
+	Fields []string
+	// Format LOG_FORMAT_JSON (padrão) ou LOG_FORMAT_COMBINED
+	Format string
+	// Output destino do formato combined (padrão: os.Stdout)
+	Output io.Writer
+	// RedactQueryParams parâmetros da query mascarados no log (padrão: DefaultRedactQueryParams)
+	RedactQueryParams []string
+	// TraceHeaderAllow quando informado apenas esses headers aparecem no log de Trace
+	TraceHeaderAllow []string
+	// TraceHeaderDeny headers que nunca aparecem no log de Trace (padrão: DefaultTraceHeaderDeny)
+	TraceHeaderDeny []string
 }
 
 func New(r *mux.Router, conf *config.Config, opts *cors.Options) *http.Server {
@@ -38,13 +55,53 @@ func NewWithLogConfig(r *mux.Router, conf *config.Config, opts *cors.Options, lo
 
 	r.Use(LoggingMiddlewareWithConfig(logCfg))
 
-	r.MethodNotAllowedHandler = defaultMethodNotAllowedHandler()
-	r.NotFoundHandler = defaultNotFoundHandler()
+	SRV_HTTP_ERROR_MODE := os.Getenv("SRV_HTTP_ERROR_MODE")
+	if SRV_HTTP_ERROR_MODE != "" {
+		conf.ERROR_MODE = SRV_HTTP_ERROR_MODE
+	}
+
+	switch strings.ToLower(conf.ERROR_MODE) {
+	case ERROR_MODE_HTTPMSG:
+		conf.ERROR_MODE = ERROR_MODE_HTTPMSG
+	case ERROR_MODE_PROBLEM, "":
+		conf.ERROR_MODE = ERROR_MODE_PROBLEM
+	default:
+		log.Info().Msg(fmt.Sprintf("Attention, The value [%s] is not valid, see the available options: (problem and httpmsg). Setting the default error mode to [problem].", conf.ERROR_MODE))
+		conf.ERROR_MODE = ERROR_MODE_PROBLEM
+	}
+
+	r.MethodNotAllowedHandler = defaultMethodNotAllowedHandler(conf.ERROR_MODE)
+	r.NotFoundHandler = defaultNotFoundHandler(conf.ERROR_MODE)
 
 	var handler http.Handler = r
 
+	// Aplicado fora do router para incluir também as respostas 404 e 405
+	loadSecurityHeadersConfig(conf)
+	if conf.SEC_HEADERS_ENABLED {
+		handler = SecurityHeadersMiddleware(conf.HttpConfig)(handler)
+	}
+
+	// cors.Options informado no código tem prioridade sobre o HttpConfig
 	if opts != nil {
-		handler = cors.New(*opts).Handler(r)
+		handler = cors.New(*opts).Handler(handler)
+	} else {
+		loadCORSConfig(conf)
+		if len(conf.CORS_ORIGINS) > 0 {
+			dc, err := NewDynamicCORS(conf)
+			if err != nil {
+				log.Fatal().Str("FunctionName", "NewWithLogConfig").Str("ERRO_CORS", "Configuração de CORS inválida").Msg(err.Error())
+			}
+			handler = dc.Handler(handler)
+
+			serverCORSMu.Lock()
+			serverCORS = dc
+			serverCORSMu.Unlock()
+		}
+	}
+
+	loadAdminConfig(conf)
+	if conf.ADMIN_PORT != "" {
+		handler = adminMetrics.middleware(handler)
 	}
 
 	SRV_HTTP_PORT := os.Getenv("SRV_HTTP_PORT")
@@ -63,6 +120,10 @@ func NewWithLogConfig(r *mux.Router, conf *config.Config, opts *cors.Options, lo
 		ErrorLog: log.DefaultLogger.Std("", 0),
 	}
 
+	if conf.ADMIN_PORT != "" {
+		startAdminServer(srv, conf)
+	}
+
 	return srv
 }
 
@@ -76,6 +137,11 @@ func LoggingMiddlewareWithConfig(cfg *LoggingMiddlewareConfig) func(http.Handler
 		}
 	}
 
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
 	return func(next http.Handler) http.Handler {
 		conf := config.NewDefaultConf()
 
@@ -89,40 +155,28 @@ func LoggingMiddlewareWithConfig(cfg *LoggingMiddlewareConfig) func(http.Handler
 				return
 			}
 
-			// Verificar se o path deve ser ignorado
-			shouldIgnore := false
-			for _, ignorePath := range cfg.IgnorePaths {
-				if strings.HasPrefix(r.URL.Path, ignorePath) {
-					shouldIgnore = true
-					break
-				}
+			// Handlers que não chamam WriteHeader respondem 200
+			if srw.status == 0 {
+				srw.status = http.StatusOK
 			}
 
-			// Se deve ignorar, retornar sem fazer log
-			if shouldIgnore {
+			// Se o path/status deve ser ignorado, retornar sem fazer log
+			if cfg.shouldIgnore(r, srw.status) {
 				return
 			}
 
-			conf.HttpConfig.Logger.Info().
-				Str("AppName", conf.AppName).
-				Str("AppVersion", conf.AppVersion).
-				Str("AppCommitShortSha", conf.AppCommitShortSha).
-				Str("UserAgent", r.UserAgent()).
-				Str("HttpVersion", r.Proto).
-				Str("Method", r.Method).
-				Str("Host", r.Host).
-				Str("RemoteAddr", r.RemoteAddr).
-				Str("UserRealRemoteAddr", userIP(r)).
-				Str("Path", r.URL.Path).
-				Str("Duration", fmt.Sprintf("%v", time.Since(start))).
-				Str("StatusCode", fmt.Sprintf("%v", srw.status)).
-				// Str("RawQuery", r.URL.RawQuery).
-				Msg(http.StatusText(srw.status))
+			if cfg.Format == LOG_FORMAT_COMBINED {
+				cfg.writeCombinedLog(output, r, srw, start)
+			} else {
+				cfg.writeJSONLog(conf.HttpConfig.Logger, conf.AppName, conf.AppVersion, conf.AppCommitShortSha, r, srw, time.Since(start))
+			}
 
 			if conf.AppLogLevel == log.TraceLevel.String() {
 				trac := conf.HttpConfig.Logger.Trace()
 				for k, v := range r.Header {
-					trac.Str(k, fmt.Sprintf("%v", v))
+					if cfg.traceHeaderAllowed(k) {
+						trac.Str(k, fmt.Sprintf("%v", v))
+					}
 				}
 				trac.Msg("Log Tracer")
 			}
@@ -146,9 +200,12 @@ func userIP(r *http.Request) string {
 	return IPAddress
 }
 
+// CONTENT_TYPE_JSON valor do header Content-Type usado nas respostas JSON
+const CONTENT_TYPE_JSON = "application/json; charset=utf-8"
+
 func ContentTypeJSONMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
 		next.ServeHTTP(w, r)
 	})
 }
@@ -156,6 +213,7 @@ func ContentTypeJSONMiddleware(next http.Handler) http.Handler {
 type statusResponseWriter struct {
 	http.ResponseWriter
 	status int
+	bytes  int64
 }
 
 func (srw *statusResponseWriter) WriteHeader(status int) {
@@ -165,6 +223,15 @@ func (srw *statusResponseWriter) WriteHeader(status int) {
 	}
 }
 
+func (srw *statusResponseWriter) Write(b []byte) (int, error) {
+	if srw.status == 0 {
+		srw.status = http.StatusOK
+	}
+	n, err := srw.ResponseWriter.Write(b)
+	srw.bytes += int64(n)
+	return n, err
+}
+
 // Hijack implementa http.Hijacker para suportar WebSocket upgrades
 func (srw *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hijacker, ok := srw.ResponseWriter.(http.Hijacker)
@@ -181,9 +248,24 @@ func (srw *statusResponseWriter) Flush() {
 	}
 }
 
+// Unwrap permite que o http.ResponseController acesse o ResponseWriter original
+func (srw *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return srw.ResponseWriter
+}
+
 type HttpMsg struct {
-	Msg  string `json:"msg"`
-	Code int    `json:"code"`
+	Msg    string                 `json:"msg"`
+	Code   int                    `json:"code"`
+	Field  string                 `json:"field,omitempty"`
+	Errors []validator.FieldError `json:"errors,omitempty"`
+}
+
+// Error implementa a interface error para que HttpMsg possa ser retornado pelos helpers
+func (m *HttpMsg) Error() string {
+	if m.Field != "" {
+		return fmt.Sprintf("%s: %s", m.Msg, m.Field)
+	}
+	return m.Msg
 }
 
 func (m *HttpMsg) toBytes() []byte {
@@ -211,16 +293,24 @@ var ErroHttpMsgMethodNotAllowed HttpMsg = HttpMsg{
 	Code: http.StatusMethodNotAllowed,
 }
 
-func defaultMethodNotAllowedHandler() http.Handler {
+func defaultMethodNotAllowedHandler(errorMode string) http.Handler {
 	return LoggingMiddlewareWithConfig(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		ErroHttpMsgMethodNotAllowed.Write(w)
+		if errorMode == ERROR_MODE_HTTPMSG {
+			w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+			ErroHttpMsgMethodNotAllowed.Write(w)
+			return
+		}
+		NewProblem(http.StatusMethodNotAllowed, "").Write(w, r)
 	}))
 }
 
-func defaultNotFoundHandler() http.Handler {
+func defaultNotFoundHandler(errorMode string) http.Handler {
 	return LoggingMiddlewareWithConfig(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		ErroHttpMsgPageNotFound.Write(w)
+		if errorMode == ERROR_MODE_HTTPMSG {
+			w.Header().Set("Content-Type", CONTENT_TYPE_JSON)
+			ErroHttpMsgPageNotFound.Write(w)
+			return
+		}
+		NewProblem(http.StatusNotFound, "").Write(w, r)
 	}))
 }
@@ -0,0 +1,289 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_IDEMPOTENCY_HEADER     = "Idempotency-Key"
+	DEFAULT_IDEMPOTENCY_KEY_PREFIX = "idempotency:"
+	DEFAULT_IDEMPOTENCY_TTL        = 24 * time.Hour
+	DEFAULT_IDEMPOTENCY_LOCK_TTL   = 30 * time.Second
+)
+
+const (
+	idempotencyStateProcessing = "processing"
+	idempotencyStateCompleted  = "completed"
+)
+
+// IdempotencyConfig configuração para o middleware de Idempotency-Key
+type IdempotencyConfig struct {
+	// Redis cliente usado para guardar as respostas (obrigatório)
+	Redis redisdb.RedisClientInterface
+	// HeaderName nome do header com a chave (padrão: Idempotency-Key)
+	HeaderName string
+	// KeyPrefix prefixo das chaves no Redis (padrão: idempotency:)
+	KeyPrefix string
+	// TTL tempo que a resposta fica disponível para replay (padrão: 24h)
+	TTL time.Duration
+	// LockTTL tempo máximo que uma requisição fica marcada como em andamento (padrão: 30s)
+	LockTTL time.Duration
+	// Methods métodos protegidos pelo middleware (padrão: POST e PATCH)
+	Methods []string
+	// Required responde 400 quando o header não é enviado
+	Required bool
+	// MaxBodySize tamanho máximo do corpo lido para o hash (padrão: DEFAULT_MAX_BODY_SIZE)
+	MaxBodySize int64
+	// Principal identifica quem fez a requisição; a chave no Redis é separada
+	// por principal para que um cliente não receba a resposta de outro que use
+	// a mesma Idempotency-Key (padrão: header Authorization)
+	Principal func(r *http.Request) string
+}
+
+type idempotencyRecord struct {
+	State    string              `json:"state"`
+	BodyHash string              `json:"body_hash"`
+	Status   int                 `json:"status,omitempty"`
+	Header   map[string][]string `json:"header,omitempty"`
+	Body     []byte              `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware guarda a primeira resposta de cada Idempotency-Key no
+// Redis e a repete nas próximas requisições com a mesma chave.
+//
+//   - Enquanto a primeira requisição ainda está em andamento responde 409.
+//   - Se a chave for reutilizada com um corpo diferente responde 422.
+//   - Respostas 5xx não são guardadas, permitindo que o cliente tente de novo.
+//
+// Exemplo de Uso:
+//
+//	payments := r.PathPrefix("/payments").Subrouter()
+//	payments.Use(httpserver.IdempotencyMiddleware(&httpserver.IdempotencyConfig{
+//	    Redis: redisConn,
+//	}))
+func IdempotencyMiddleware(cfg *IdempotencyConfig) func(http.Handler) http.Handler {
+	if cfg == nil || cfg.Redis == nil {
+		log.Fatal().Str("FunctionName", "IdempotencyMiddleware").Msg("IdempotencyConfig.Redis é obrigatório!")
+	}
+
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = DEFAULT_IDEMPOTENCY_HEADER
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = DEFAULT_IDEMPOTENCY_KEY_PREFIX
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DEFAULT_IDEMPOTENCY_TTL
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = DEFAULT_IDEMPOTENCY_LOCK_TTL
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DEFAULT_MAX_BODY_SIZE
+	}
+	if cfg.Principal == nil {
+		cfg.Principal = func(r *http.Request) string {
+			return r.Header.Get("Authorization")
+		}
+	}
+
+	methods := make(map[string]bool, len(cfg.Methods))
+	for _, m := range cfg.Methods {
+		methods[m] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !methods[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			idemKey := r.Header.Get(cfg.HeaderName)
+			if idemKey == "" {
+				if cfg.Required {
+					NewProblem(http.StatusBadRequest, "header "+cfg.HeaderName+" is required").Write(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, cfg.MaxBodySize))
+			if err != nil {
+				WriteProblem(w, r, decodeErrorToHttpMsg(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(body)
+			bodyHash := hex.EncodeToString(sum[:])
+			// O principal entra como hash para não gravar tokens nas chaves do Redis
+			principal := sha256.Sum256([]byte(cfg.Principal(r)))
+			redisKey := cfg.KeyPrefix + hex.EncodeToString(principal[:8]) + ":" + r.Method + ":" + r.URL.Path + ":" + idemKey
+			ctx := r.Context()
+
+			acquired, err := acquireIdempotencyKey(ctx, cfg, redisKey, bodyHash)
+			if err != nil {
+				log.Error().Str("FunctionName", "IdempotencyMiddleware").Str("ERRO_REDIS", "Erro ao tentar reservar a chave").Msg(err.Error())
+				NewProblem(http.StatusServiceUnavailable, "").Write(w, r)
+				return
+			}
+
+			if !acquired {
+				replayIdempotentResponse(w, r, cfg, redisKey, bodyHash)
+				return
+			}
+
+			// Headers definidos pelos middlewares externos (X-Request-Id, CORS,
+			// nonce do CSP...) não são guardados, eles são gerados de novo no replay
+			outer := w.Header().Clone()
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+				rec.snapshot = w.Header().Clone()
+			}
+			rec.snapshot = addedHeaders(outer, rec.snapshot)
+
+			// Usa um contexto próprio para gravar mesmo que o cliente tenha desconectado
+			saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if rec.status >= http.StatusInternalServerError {
+				cfg.Redis.GetClient().Del(saveCtx, redisKey)
+				return
+			}
+
+			data, err := json.Marshal(&idempotencyRecord{
+				State:    idempotencyStateCompleted,
+				BodyHash: bodyHash,
+				Status:   rec.status,
+				Header:   rec.snapshot,
+				Body:     rec.body.Bytes(),
+			})
+			if err != nil {
+				log.Error().Str("FunctionName", "IdempotencyMiddleware").Msg(err.Error())
+				return
+			}
+
+			if ok := cfg.Redis.SaveData(saveCtx, redisKey, data, cfg.TTL); !ok {
+				log.Error().Str("FunctionName", "IdempotencyMiddleware").Str("ERRO_REDIS", "Erro ao tentar salvar a resposta").Str("Key", redisKey).Msg("SaveData failed")
+			}
+		})
+	}
+}
+
+func acquireIdempotencyKey(ctx context.Context, cfg *IdempotencyConfig, redisKey, bodyHash string) (bool, error) {
+	data, err := json.Marshal(&idempotencyRecord{
+		State:    idempotencyStateProcessing,
+		BodyHash: bodyHash,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return cfg.Redis.GetClient().SetNX(ctx, redisKey, data, cfg.LockTTL).Result()
+}
+
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, cfg *IdempotencyConfig, redisKey, bodyHash string) {
+	data, err := cfg.Redis.GetClient().Get(r.Context(), redisKey).Bytes()
+	if err != nil {
+		// A chave expirou entre o SETNX e o GET, o cliente pode tentar novamente
+		NewProblem(http.StatusConflict, "request with this "+cfg.HeaderName+" is being processed").Write(w, r)
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		WriteProblem(w, r, errors.New("invalid idempotency record: "+err.Error()))
+		return
+	}
+
+	if record.BodyHash != bodyHash {
+		NewProblem(http.StatusUnprocessableEntity, cfg.HeaderName+" was already used with a different request body").Write(w, r)
+		return
+	}
+
+	if record.State != idempotencyStateCompleted {
+		NewProblem(http.StatusConflict, "request with this "+cfg.HeaderName+" is being processed").Write(w, r)
+		return
+	}
+
+	for k, values := range record.Header {
+		w.Header()[k] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.Status)
+	w.Write(record.Body)
+}
+
+// addedHeaders retorna os headers de after que não existiam, ou tinham outro
+// valor, em before
+func addedHeaders(before, after http.Header) http.Header {
+	added := http.Header{}
+	for k, values := range after {
+		if !slices.Equal(before[k], values) {
+			added[k] = values
+		}
+	}
+	return added
+}
+
+// responseRecorder repassa a resposta para o cliente e guarda uma cópia
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	snapshot http.Header
+	body     bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status != 0 {
+		return
+	}
+	rr.status = status
+	rr.snapshot = rr.ResponseWriter.Header().Clone()
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.WriteHeader(http.StatusOK)
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+// Flush implementa http.Flusher para suportar streaming
+func (rr *responseRecorder) Flush() {
+	if flusher, ok := rr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap permite que o http.ResponseController acesse o ResponseWriter original
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
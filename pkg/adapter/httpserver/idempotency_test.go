@@ -0,0 +1,179 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	rdb, _ := newTestRedis(t)
+
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.URL.Path == "/slow" {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Location", "/payments/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":` + string(rune('0'+n)) + `}`))
+	})
+
+	// Simula um middleware externo que define headers antes do handler
+	var requestID atomic.Int32
+	outer := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", string(rune('a'+requestID.Add(1))))
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	srv := outer(IdempotencyMiddleware(&IdempotencyConfig{Redis: rdb, Required: true})(handler))
+
+	do := func(path, key, auth, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(DEFAULT_IDEMPOTENCY_HEADER, key)
+		}
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		if w := do("/payments", "", "", `{}`); w.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		first := do("/payments", "k1", "Bearer a", `{"v":1}`)
+		second := do("/payments", "k1", "Bearer a", `{"v":1}`)
+
+		if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+			t.Fatalf("status = %d/%d", first.Code, second.Code)
+		}
+		if first.Body.String() != second.Body.String() {
+			t.Errorf("body = %q, replay = %q", first.Body.String(), second.Body.String())
+		}
+		if second.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("missing Idempotent-Replayed")
+		}
+		if got := second.Header().Values("Location"); len(got) != 1 || got[0] != "/payments/1" {
+			t.Errorf("Location = %v", got)
+		}
+		// O header do middleware externo não é duplicado nem repetido com o valor antigo
+		if got := second.Header().Values("X-Request-Id"); len(got) != 1 || got[0] == first.Header().Get("X-Request-Id") {
+			t.Errorf("X-Request-Id = %v, first = %q", got, first.Header().Get("X-Request-Id"))
+		}
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		if w := do("/payments", "k1", "Bearer a", `{"v":2}`); w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want 422", w.Code)
+		}
+	})
+
+	t.Run("other principal", func(t *testing.T) {
+		before := calls.Load()
+		w := do("/payments", "k1", "Bearer b", `{"v":1}`)
+		if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("status = %d, replayed = %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+		}
+		if calls.Load() != before+1 {
+			t.Error("handler was not called for another principal")
+		}
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		defer close(release)
+
+		done := make(chan *httptest.ResponseRecorder, 1)
+		go func() { done <- do("/slow", "k2", "", `{}`) }()
+
+		// O handler só começa depois de a chave ser reservada
+		select {
+		case <-started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("first request did not reach the handler")
+		}
+
+		// Se a chave não estivesse reservada a segunda requisição ficaria presa no
+		// release, por isso ela também roda fora da goroutine do teste
+		second := make(chan *httptest.ResponseRecorder, 1)
+		go func() { second <- do("/slow", "k2", "", `{}`) }()
+
+		select {
+		case w := <-second:
+			if w.Code != http.StatusConflict {
+				t.Fatalf("status = %d, want 409", w.Code)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("concurrent request reached the handler")
+		}
+
+		release <- struct{}{}
+		if w := <-done; w.Code != http.StatusCreated {
+			t.Fatalf("status = %d", w.Code)
+		}
+	})
+
+	t.Run("5xx not stored", func(t *testing.T) {
+		before := calls.Load()
+		do("/fail", "k3", "", `{}`)
+		do("/fail", "k3", "", `{}`)
+		if calls.Load() != before+2 {
+			t.Errorf("calls = %d, want 2", calls.Load()-before)
+		}
+	})
+
+	t.Run("other methods pass through", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/payments", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status = %d", w.Code)
+		}
+	})
+}
+
+func TestIdempotencyPrincipal(t *testing.T) {
+	rdb, _ := newTestRedis(t)
+
+	var calls atomic.Int32
+	srv := IdempotencyMiddleware(&IdempotencyConfig{
+		Redis:     rdb,
+		Principal: func(r *http.Request) string { return r.Header.Get("X-Tenant") },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("ok"))
+	}))
+
+	for _, tenant := range []string{"a", "a", "b"} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x"))
+		r.Header.Set(DEFAULT_IDEMPOTENCY_HEADER, "same")
+		r.Header.Set("X-Tenant", tenant)
+		srv.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2 (one per tenant)", calls.Load())
+	}
+}
@@ -0,0 +1,266 @@
+package httpserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/phuslu/log"
+)
+
+const (
+	// LOG_FORMAT_JSON log estruturado usando o Logger do HttpConfig (padrão)
+	LOG_FORMAT_JSON = "json"
+	// LOG_FORMAT_COMBINED uma linha por requisição no formato Apache combined
+	LOG_FORMAT_COMBINED = "combined"
+)
+
+// Campos disponíveis em LoggingMiddlewareConfig.Fields
+const (
+	LOG_FIELD_APP_NAME              = "AppName"
+	LOG_FIELD_APP_VERSION           = "AppVersion"
+	LOG_FIELD_APP_COMMIT_SHORT_SHA  = "AppCommitShortSha"
+	LOG_FIELD_USER_AGENT            = "UserAgent"
+	LOG_FIELD_HTTP_VERSION          = "HttpVersion"
+	LOG_FIELD_METHOD                = "Method"
+	LOG_FIELD_HOST                  = "Host"
+	LOG_FIELD_REMOTE_ADDR           = "RemoteAddr"
+	LOG_FIELD_USER_REAL_REMOTE_ADDR = "UserRealRemoteAddr"
+	LOG_FIELD_PATH                  = "Path"
+	LOG_FIELD_ROUTE                 = "Route"
+	LOG_FIELD_QUERY                 = "Query"
+	LOG_FIELD_REFERER               = "Referer"
+	LOG_FIELD_DURATION              = "Duration"
+	LOG_FIELD_STATUS_CODE           = "StatusCode"
+	LOG_FIELD_BYTES_WRITTEN         = "BytesWritten"
+)
+
+// DefaultLogFields campos usados quando LoggingMiddlewareConfig.Fields está vazio
+var DefaultLogFields = []string{
+	LOG_FIELD_APP_NAME,
+	LOG_FIELD_APP_VERSION,
+	LOG_FIELD_APP_COMMIT_SHORT_SHA,
+	LOG_FIELD_USER_AGENT,
+	LOG_FIELD_HTTP_VERSION,
+	LOG_FIELD_METHOD,
+	LOG_FIELD_HOST,
+	LOG_FIELD_REMOTE_ADDR,
+	LOG_FIELD_USER_REAL_REMOTE_ADDR,
+	LOG_FIELD_PATH,
+	LOG_FIELD_ROUTE,
+	LOG_FIELD_DURATION,
+	LOG_FIELD_STATUS_CODE,
+	LOG_FIELD_BYTES_WRITTEN,
+}
+
+// DefaultRedactQueryParams parâmetros da query mascarados quando RedactQueryParams está vazio
+var DefaultRedactQueryParams = []string{"token", "access_token", "password", "secret", "api_key", "apikey"}
+
+// DefaultTraceHeaderDeny headers nunca exibidos no log de Trace quando TraceHeaderDeny está vazio
+var DefaultTraceHeaderDeny = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+const LOG_REDACTED_VALUE = "REDACTED"
+
+// LogPathFilter filtro de log por path e status
+type LogPathFilter struct {
+	// Path prefixo (ex: "/health") ou glob (ex: "/api/*/internal") do path
+	Path string
+	// LogStatus quando informado apenas esses status geram log nesse path.
+	// Aceita status exatos ("404") e classes ("5xx"). Vazio ignora todos os logs do path.
+	LogStatus []string
+}
+
+func (f *LogPathFilter) matchPath(reqPath string) bool {
+	return matchLogPath(f.Path, reqPath)
+}
+
+func (f *LogPathFilter) ignore(status int) bool {
+	if len(f.LogStatus) == 0 {
+		return true
+	}
+
+	code := strconv.Itoa(status)
+	for _, s := range f.LogStatus {
+		s = strings.ToLower(s)
+		if s == code {
+			return false
+		}
+		if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] == code[0] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchLogPath usa glob quando o padrão tem metacaracteres e prefixo nos demais casos
+func matchLogPath(pattern, reqPath string) bool {
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, err := path.Match(pattern, reqPath)
+		return err == nil && ok
+	}
+	return strings.HasPrefix(reqPath, pattern)
+}
+
+func (cfg *LoggingMiddlewareConfig) shouldIgnore(r *http.Request, status int) bool {
+	for _, ignorePath := range cfg.IgnorePaths {
+		if matchLogPath(ignorePath, r.URL.Path) {
+			return true
+		}
+	}
+
+	for i := range cfg.Filters {
+		if cfg.Filters[i].matchPath(r.URL.Path) {
+			return cfg.Filters[i].ignore(status)
+		}
+	}
+
+	return false
+}
+
+func (cfg *LoggingMiddlewareConfig) redactedQuery(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return ""
+	}
+
+	redact := cfg.RedactQueryParams
+	if len(redact) == 0 {
+		redact = DefaultRedactQueryParams
+	}
+
+	query := r.URL.Query()
+	for name := range query {
+		for _, secret := range redact {
+			if strings.EqualFold(name, secret) {
+				query[name] = []string{LOG_REDACTED_VALUE}
+			}
+		}
+	}
+
+	return query.Encode()
+}
+
+func (cfg *LoggingMiddlewareConfig) traceHeaderAllowed(name string) bool {
+	deny := cfg.TraceHeaderDeny
+	if len(deny) == 0 {
+		deny = DefaultTraceHeaderDeny
+	}
+	for _, d := range deny {
+		if strings.EqualFold(d, name) {
+			return false
+		}
+	}
+
+	if len(cfg.TraceHeaderAllow) == 0 {
+		return true
+	}
+	for _, a := range cfg.TraceHeaderAllow {
+		if strings.EqualFold(a, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	if tpl, err := route.GetPathTemplate(); err == nil {
+		return tpl
+	}
+
+	return ""
+}
+
+func (cfg *LoggingMiddlewareConfig) writeJSONLog(logger *log.Logger, appName, appVersion, commit string, r *http.Request, srw *statusResponseWriter, duration time.Duration) {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultLogFields
+	}
+
+	entry := logger.Info()
+	for _, field := range fields {
+		switch field {
+		case LOG_FIELD_APP_NAME:
+			entry.Str(field, appName)
+		case LOG_FIELD_APP_VERSION:
+			entry.Str(field, appVersion)
+		case LOG_FIELD_APP_COMMIT_SHORT_SHA:
+			entry.Str(field, commit)
+		case LOG_FIELD_USER_AGENT:
+			entry.Str(field, r.UserAgent())
+		case LOG_FIELD_HTTP_VERSION:
+			entry.Str(field, r.Proto)
+		case LOG_FIELD_METHOD:
+			entry.Str(field, r.Method)
+		case LOG_FIELD_HOST:
+			entry.Str(field, r.Host)
+		case LOG_FIELD_REMOTE_ADDR:
+			entry.Str(field, r.RemoteAddr)
+		case LOG_FIELD_USER_REAL_REMOTE_ADDR:
+			entry.Str(field, userIP(r))
+		case LOG_FIELD_PATH:
+			entry.Str(field, r.URL.Path)
+		case LOG_FIELD_ROUTE:
+			entry.Str(field, routeTemplate(r))
+		case LOG_FIELD_QUERY:
+			entry.Str(field, cfg.redactedQuery(r))
+		case LOG_FIELD_REFERER:
+			entry.Str(field, r.Referer())
+		case LOG_FIELD_DURATION:
+			// Duração em milissegundos
+			entry.Dur(field, duration)
+		case LOG_FIELD_STATUS_CODE:
+			entry.Int(field, srw.status)
+		case LOG_FIELD_BYTES_WRITTEN:
+			entry.Int64(field, srw.bytes)
+		}
+	}
+
+	entry.Msg(http.StatusText(srw.status))
+}
+
+// writeCombinedLog escreve no formato Apache combined:
+// host - - [tempo] "método uri protocolo" status bytes "referer" "user-agent"
+func (cfg *LoggingMiddlewareConfig) writeCombinedLog(out io.Writer, r *http.Request, srw *statusResponseWriter, start time.Time) {
+	uri := r.URL.EscapedPath()
+	if query := cfg.redactedQuery(r); query != "" {
+		uri += "?" + query
+	}
+
+	size := "-"
+	if srw.bytes > 0 {
+		size = strconv.FormatInt(srw.bytes, 10)
+	}
+
+	host := userIP(r)
+	if idx := strings.IndexByte(host, ','); idx >= 0 {
+		host = strings.TrimSpace(host[:idx])
+	}
+
+	fmt.Fprintf(out, "%s - - [%s] \"%s %s %s\" %d %s %q %q\n",
+		host,
+		start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, uri, r.Proto,
+		srw.status,
+		size,
+		orDash(r.Referer()),
+		orDash(r.UserAgent()),
+	)
+}
+
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
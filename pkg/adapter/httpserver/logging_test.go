@@ -0,0 +1,179 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/phuslu/log"
+)
+
+func TestLoggingShouldIgnore(t *testing.T) {
+	cfg := &LoggingMiddlewareConfig{
+		IgnorePaths: []string{"/assets/", "/api/*/metrics"},
+		Filters: []LogPathFilter{
+			{Path: "/health", LogStatus: []string{"5xx"}},
+			{Path: "/login", LogStatus: []string{"401", "5XX"}},
+			{Path: "/silent"},
+		},
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		want   bool
+	}{
+		{"/assets/app.js", 200, true},
+		{"/api/v1/metrics", 200, true},
+		{"/api/v1/users", 200, false},
+		{"/health", 200, true},
+		{"/health", 503, false},
+		{"/login", 200, true},
+		{"/login", 401, false},
+		{"/login", 500, false},
+		{"/silent", 500, true},
+		{"/other", 404, false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if got := cfg.shouldIgnore(r, tt.status); got != tt.want {
+			t.Errorf("shouldIgnore(%s, %d) = %v, want %v", tt.path, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestLoggingRedactedQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		redact []string
+		query  string
+		want   string
+	}{
+		{name: "empty", query: "", want: ""},
+		{name: "default", query: "page=1&Token=abc", want: "Token=" + LOG_REDACTED_VALUE + "&page=1"},
+		{name: "custom", redact: []string{"cpf"}, query: "cpf=1&token=x", want: "cpf=" + LOG_REDACTED_VALUE + "&token=x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &LoggingMiddlewareConfig{RedactQueryParams: tt.redact}
+			r := httptest.NewRequest(http.MethodGet, "/x?"+tt.query, nil)
+			if got := cfg.redactedQuery(r); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggingTraceHeaderAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    LoggingMiddlewareConfig
+		header string
+		want   bool
+	}{
+		{name: "default allows", header: "Accept", want: true},
+		{name: "default denies authorization", header: "authorization", want: false},
+		{name: "default denies cookie", header: "Cookie", want: false},
+		{name: "allow list", cfg: LoggingMiddlewareConfig{TraceHeaderAllow: []string{"X-Request-Id"}}, header: "Accept", want: false},
+		{name: "allow list match", cfg: LoggingMiddlewareConfig{TraceHeaderAllow: []string{"X-Request-Id"}}, header: "x-request-id", want: true},
+		{name: "deny wins", cfg: LoggingMiddlewareConfig{TraceHeaderAllow: []string{"Cookie"}}, header: "Cookie", want: false},
+		{name: "custom deny", cfg: LoggingMiddlewareConfig{TraceHeaderDeny: []string{"X-Secret"}}, header: "X-Secret", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.traceHeaderAllowed(tt.header); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggingJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &log.Logger{Level: log.InfoLevel, Writer: &log.IOWriter{Writer: &buf}}
+
+	router := mux.NewRouter()
+	var captured *http.Request
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		captured = r
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/42?token=abc", nil))
+
+	cfg := &LoggingMiddlewareConfig{Fields: []string{LOG_FIELD_ROUTE, LOG_FIELD_QUERY, LOG_FIELD_STATUS_CODE, LOG_FIELD_BYTES_WRITTEN, LOG_FIELD_DURATION}}
+	cfg.writeJSONLog(logger, "app", "1.0", "abc", captured, &statusResponseWriter{status: http.StatusCreated, bytes: 5}, 1500*time.Millisecond)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+
+	want := map[string]interface{}{
+		LOG_FIELD_ROUTE:         "/users/{id}",
+		LOG_FIELD_QUERY:         "token=" + LOG_REDACTED_VALUE,
+		LOG_FIELD_STATUS_CODE:   float64(201),
+		LOG_FIELD_BYTES_WRITTEN: float64(5),
+		"message":               "Created",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %v (%T), want %v", k, entry[k], entry[k], v)
+		}
+	}
+	if _, ok := entry[LOG_FIELD_DURATION].(float64); !ok {
+		t.Errorf("Duration = %v, want number", entry[LOG_FIELD_DURATION])
+	}
+	if _, ok := entry[LOG_FIELD_USER_AGENT]; ok {
+		t.Error("UserAgent should not be logged")
+	}
+}
+
+func TestLoggingCombinedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	mw := LoggingMiddlewareWithConfig(&LoggingMiddlewareConfig{
+		Enabled:     true,
+		Format:      LOG_FORMAT_COMBINED,
+		Output:      &buf,
+		IgnorePaths: []string{"/ignored"},
+	})
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/path?password=x", nil)
+	r.Header.Set("User-Agent", "curl/8")
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ignored", nil))
+
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 {
+		t.Fatalf("lines = %q, want only one", line)
+	}
+	for _, want := range []string{`10.0.0.1 - - [`, `"GET /path?password=REDACTED HTTP/1.1" 200 5 "-" "curl/8"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line = %q, want %q", line, want)
+		}
+	}
+}
+
+func TestLoggingDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	handler := LoggingMiddlewareWithConfig(&LoggingMiddlewareConfig{Format: LOG_FORMAT_COMBINED, Output: &buf})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("logged %q with Enabled=false", buf.String())
+	}
+}
@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+)
+
+// TestMain prepara a configuração global usada pelos middlewares, como o New faz
+func TestMain(m *testing.M) {
+	conf := config.NewDefaultConf()
+	conf.SetAppLogLevel("error")
+	if conf.HttpConfig == nil {
+		conf.HttpConfig = &config.HttpConfig{}
+	}
+	conf.HttpConfig.Logger = conf.GetGlobalLogger()
+
+	os.Exit(m.Run())
+}
+
+// newTestRedis cria um cliente redisdb conectado a um miniredis exclusivo do teste
+func newTestRedis(t *testing.T) (redisdb.RedisClientInterface, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	t.Setenv("SRV_RDB_HOST", host)
+	t.Setenv("SRV_RDB_PORT", port)
+
+	conf := config.NewDefaultConf()
+	if conf.RedisDBConfig == nil {
+		conf.RedisDBConfig = &config.RedisDBConfig{}
+	}
+
+	rdb, err := redisdb.NewWithError(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rdb.Close() })
+
+	return rdb.(redisdb.RedisClientInterface), mr
+}
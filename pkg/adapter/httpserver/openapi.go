@@ -0,0 +1,585 @@
+package httpserver
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+	"github.com/gorilla/mux"
+	"github.com/phuslu/log"
+)
+
+const (
+	OPENAPI_VERSION         = "3.1.0"
+	DEFAULT_OPENAPI_PATH    = "/openapi.json"
+	openAPIProblemSchemaRef = "#/components/schemas/Problem"
+)
+
+var (
+	pathParamRegex      = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+	schemaNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// Operation documentação de uma rota registrada com OpenAPI.Document
+type Operation struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	// Request valor de exemplo do tipo do corpo (ex: CreateUser{}), nil quando não há corpo
+	Request interface{}
+	// Query struct com os parâmetros da query, usando a tag `query` (ou `json`) para o nome
+	Query interface{}
+	// Responses tipo do corpo por status (ex: {201: User{}, 204: nil})
+	Responses  map[int]interface{}
+	Deprecated bool
+}
+
+// OpenAPI gera um documento OpenAPI 3.1 a partir das rotas documentadas
+type OpenAPI struct {
+	Title       string
+	Version     string
+	Description string
+
+	conf *config.Config
+
+	mu         sync.RWMutex
+	operations []*documentedRoute
+}
+
+type documentedRoute struct {
+	route *mux.Route
+	op    *Operation
+}
+
+// NewOpenAPI cria o gerador usando o AppName e AppVersion do Config no info do documento.
+//
+// Variável de ambiente:
+//
+//	SRV_HTTP_OPENAPI_PATH  path em que o documento é servido pelo Mount (padrão: /openapi.json)
+//
+// Exemplo de Uso:
+//
+//	doc := httpserver.NewOpenAPI(conf)
+//
+//	doc.Document(router.HandleFunc("/api/v1/users/{id}", getUser).Methods("GET"), &httpserver.Operation{
+//	    Summary:   "Busca um usuário",
+//	    Tags:      []string{"users"},
+//	    Responses: map[int]interface{}{200: User{}, 404: nil},
+//	})
+//	doc.Document(router.HandleFunc("/api/v1/users", createUser).Methods("POST"), &httpserver.Operation{
+//	    Request:   CreateUser{},
+//	    Responses: map[int]interface{}{201: User{}},
+//	})
+//
+//	doc.Mount(router)
+//	router.Use(doc.ValidationMiddleware())
+func NewOpenAPI(conf *config.Config) *OpenAPI {
+	SRV_HTTP_OPENAPI_PATH := os.Getenv("SRV_HTTP_OPENAPI_PATH")
+	if SRV_HTTP_OPENAPI_PATH != "" {
+		conf.OPENAPI_PATH = SRV_HTTP_OPENAPI_PATH
+	} else if conf.OPENAPI_PATH == "" {
+		conf.OPENAPI_PATH = DEFAULT_OPENAPI_PATH
+	}
+
+	version := conf.AppVersion
+	if version == "" {
+		version = "0.0.0"
+	}
+
+	return &OpenAPI{
+		// O setAppName adiciona um sufixo @id no AppName
+		Title:   strings.Split(conf.AppName, "@")[0],
+		Version: version,
+		conf:    conf,
+	}
+}
+
+// Document associa a documentação à rota e retorna a própria rota. O path e os
+// métodos são lidos da rota no momento em que o documento é gerado.
+func (o *OpenAPI) Document(route *mux.Route, op *Operation) *mux.Route {
+	if op == nil {
+		op = &Operation{}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.operations = append(o.operations, &documentedRoute{route: route, op: op})
+
+	return route
+}
+
+// Mount serve o documento em GET no path configurado em OPENAPI_PATH
+func (o *OpenAPI) Mount(r *mux.Router) {
+	r.Handle(o.conf.OPENAPI_PATH, o.Handler()).Methods(http.MethodGet)
+}
+
+// Handler serve o documento gerado em JSON
+func (o *OpenAPI) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, o.Generate())
+	})
+}
+
+// Generate monta o documento OpenAPI com as rotas documentadas até o momento
+func (o *OpenAPI) Generate() map[string]interface{} {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	sg := &schemaGenerator{components: map[string]interface{}{}}
+	sg.components["Problem"] = problemSchema()
+
+	paths := map[string]map[string]interface{}{}
+
+	for _, dr := range o.operations {
+		tpl, err := dr.route.GetPathTemplate()
+		if err != nil {
+			log.Error().Str("FunctionName", "OpenAPI.Generate").Msg(err.Error())
+			continue
+		}
+
+		methods, err := dr.route.GetMethods()
+		if err != nil {
+			methods = []string{http.MethodGet}
+		}
+
+		path := pathParamRegex.ReplaceAllString(tpl, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+
+		for _, method := range methods {
+			if method == http.MethodOptions && len(methods) > 1 {
+				continue
+			}
+			paths[path][strings.ToLower(method)] = sg.operation(dr.op, tpl)
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": OPENAPI_VERSION,
+		"info": map[string]interface{}{
+			"title":       o.Title,
+			"version":     o.Version,
+			"description": o.Description,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": sg.components},
+	}
+
+	return doc
+}
+
+func (sg *schemaGenerator) operation(op *Operation, tpl string) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	if op.OperationID != "" {
+		result["operationId"] = op.OperationID
+	}
+	if op.Summary != "" {
+		result["summary"] = op.Summary
+	}
+	if op.Description != "" {
+		result["description"] = op.Description
+	}
+	if len(op.Tags) > 0 {
+		result["tags"] = op.Tags
+	}
+	if op.Deprecated {
+		result["deprecated"] = true
+	}
+
+	var params []interface{}
+	for _, match := range pathParamRegex.FindAllStringSubmatch(tpl, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	if op.Query != nil {
+		params = append(params, sg.queryParams(reflect.TypeOf(op.Query))...)
+	}
+	if len(params) > 0 {
+		result["parameters"] = params
+	}
+
+	if op.Request != nil {
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": sg.schema(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{}
+	for status, body := range op.Responses {
+		resp := map[string]interface{}{"description": http.StatusText(status)}
+		if body != nil {
+			resp["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": sg.schema(reflect.TypeOf(body))},
+			}
+		}
+		responses[strconv.Itoa(status)] = resp
+	}
+	responses["default"] = map[string]interface{}{
+		"description": "Erro",
+		"content": map[string]interface{}{
+			"application/problem+json": map[string]interface{}{"schema": map[string]interface{}{"$ref": openAPIProblemSchemaRef}},
+		},
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+type schemaGenerator struct {
+	components map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema converte um tipo Go em JSON Schema. Structs nomeadas viram
+// componentes referenciados com $ref.
+func (sg *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": sg.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": sg.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sg.structSchema(t)
+		}
+
+		name := schemaName(t)
+		if _, ok := sg.components[name]; !ok {
+			// Registra antes de gerar para suportar tipos recursivos
+			sg.components[name] = map[string]interface{}{}
+			sg.components[name] = sg.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (sg *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	sg.collectFields(t, properties, &required)
+
+	result := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		result["required"] = required
+	}
+
+	return result
+}
+
+func (sg *schemaGenerator) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Campos embutidos sem tag json têm os campos promovidos, como no
+		// encoding/json, mesmo quando o tipo embutido não é exportado
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				sg.collectFields(ft, properties, required)
+				continue
+			}
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		prop := sg.schema(sf.Type)
+		if applyValidateRules(prop, sf) {
+			*required = append(*required, name)
+		}
+		properties[name] = prop
+	}
+}
+
+// queryParams documenta os campos da struct Query como parâmetros "in: query"
+func (sg *schemaGenerator) queryParams(t reflect.Type) []interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []interface{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := queryParamName(sf)
+		if name == "-" {
+			continue
+		}
+
+		schema := sg.schema(sf.Type)
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "query",
+			"required": applyValidateRules(schema, sf),
+			"schema":   schema,
+		})
+	}
+
+	return params
+}
+
+func queryParamName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("query"), ",")
+	if name == "" {
+		name, _, _ = strings.Cut(sf.Tag.Get("json"), ",")
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name
+}
+
+// applyValidateRules traduz as tags validate para o schema e informa se o campo é required
+func applyValidateRules(schema map[string]interface{}, sf reflect.StructField) bool {
+	tag := sf.Tag.Get(validator.TAG_NAME)
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	required := false
+	for _, rule := range validator.SplitRules(tag) {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch schema["type"] {
+			case "string":
+				schema[name+"Length"] = int(n)
+			case "array":
+				schema[name+"Items"] = int(n)
+			case "object":
+				schema[name+"Properties"] = int(n)
+			default:
+				schema[map[string]string{"min": "minimum", "max": "maximum"}[name]] = n
+			}
+		case "enum":
+			schema["enum"] = strings.Split(param, "|")
+		case "regex":
+			schema["pattern"] = param
+		}
+	}
+
+	return required
+}
+
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if pkg := t.PkgPath(); pkg != "" {
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	return schemaNameSanitizer.ReplaceAllString(name, "_")
+}
+
+func problemSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":     map[string]interface{}{"type": "string"},
+			"title":    map[string]interface{}{"type": "string"},
+			"status":   map[string]interface{}{"type": "integer"},
+			"detail":   map[string]interface{}{"type": "string"},
+			"instance": map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// ValidationMiddleware valida as requisições das rotas documentadas contra a
+// documentação: corpo JSON (sem campos desconhecidos e respeitando as tags
+// validate) e parâmetros obrigatórios da query. Deve ser adicionado com
+// router.Use para ter acesso à rota encontrada.
+//
+// Com AppMode production o middleware não faz nada; a validação serve para
+// encontrar divergências entre a documentação e os clientes em developer e homologation.
+func (o *OpenAPI) ValidationMiddleware() mux.MiddlewareFunc {
+	if o.conf.AppMode == config.PRODUCTION {
+		log.Info().Str("FunctionName", "OpenAPI.ValidationMiddleware").Msg("OpenAPI request validation is disabled in production mode")
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op := o.operationFor(mux.CurrentRoute(r))
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := validateQuery(r, op.Query); err != nil {
+				WriteProblem(w, r, err)
+				return
+			}
+
+			if op.Request != nil && r.Body != nil {
+				body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, DEFAULT_MAX_BODY_SIZE))
+				if err != nil {
+					WriteProblem(w, r, decodeErrorToHttpMsg(err))
+					return
+				}
+
+				// O handler precisa ler o mesmo corpo depois da validação
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				t := reflect.TypeOf(op.Request)
+				for t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+				dst := reflect.New(t).Interface()
+
+				check := r.Clone(r.Context())
+				check.Body = io.NopCloser(bytes.NewReader(body))
+				if len(body) == 0 {
+					check.Body = http.NoBody
+				}
+
+				if err := decodeJSONInto(check, &DecodeLimits{DisallowUnknownFields: true}, dst); err != nil {
+					log.Warn().Str("FunctionName", "OpenAPI.ValidationMiddleware").Str("Method", r.Method).Str("Path", r.URL.Path).Msg(err.Error())
+					WriteProblem(w, r, err)
+					return
+				}
+				if err := validateDecoded(dst); err != nil {
+					log.Warn().Str("FunctionName", "OpenAPI.ValidationMiddleware").Str("Method", r.Method).Str("Path", r.URL.Path).Msg(err.Error())
+					WriteProblem(w, r, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (o *OpenAPI) operationFor(route *mux.Route) *Operation {
+	if route == nil {
+		return nil
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	for _, dr := range o.operations {
+		if dr.route == route {
+			return dr.op
+		}
+	}
+	return nil
+}
+
+// validateQuery confere os parâmetros required e o tipo dos numéricos e booleanos
+func validateQuery(r *http.Request, query interface{}) error {
+	if query == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(query)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	values := r.URL.Query()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := queryParamName(sf)
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+
+		value := values.Get(name)
+		if value == "" {
+			if applyValidateRules(map[string]interface{}{}, sf) {
+				return &HttpMsg{Msg: "Erro Missing Query Parameter", Code: http.StatusBadRequest, Field: name}
+			}
+			continue
+		}
+
+		var err error
+		switch sf.Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			_, err = strconv.ParseInt(value, 10, 64)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			_, err = strconv.ParseUint(value, 10, 64)
+		case reflect.Float32, reflect.Float64:
+			_, err = strconv.ParseFloat(value, 64)
+		case reflect.Bool:
+			_, err = strconv.ParseBool(value)
+		}
+		if err != nil {
+			return &HttpMsg{Msg: "Erro Invalid Query Parameter", Code: http.StatusBadRequest, Field: name}
+		}
+	}
+
+	return nil
+}
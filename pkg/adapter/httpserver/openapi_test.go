@@ -0,0 +1,196 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/gorilla/mux"
+)
+
+type openapiBase struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type openapiUser struct {
+	openapiBase
+	Name    string            `json:"name" validate:"required,min=3,max=50"`
+	Role    *string           `json:"role,omitempty" validate:"enum=admin|user"`
+	Tags    []string          `json:"tags" validate:"max=5"`
+	Meta    map[string]string `json:"meta"`
+	Manager *openapiUser      `json:"manager,omitempty"`
+	Secret  string            `json:"-"`
+}
+
+type openapiListQuery struct {
+	Page   int    `query:"page" validate:"required"`
+	Active bool   `json:"active"`
+	Search string `query:"q"`
+}
+
+func newTestOpenAPI(t *testing.T, mode string) (*OpenAPI, *mux.Router) {
+	t.Helper()
+	t.Setenv("SRV_HTTP_OPENAPI_PATH", "")
+
+	doc := NewOpenAPI(&config.Config{AppName: "users@abc", AppVersion: "1.2.0", AppMode: mode, HttpConfig: &config.HttpConfig{}})
+
+	r := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+
+	doc.Document(r.HandleFunc("/users/{id:[0-9]+}", ok).Methods(http.MethodGet), &Operation{
+		OperationID: "getUser",
+		Tags:        []string{"users"},
+		Responses:   map[int]interface{}{200: openapiUser{}, 404: nil},
+	})
+	doc.Document(r.HandleFunc("/users", ok).Methods(http.MethodPost), &Operation{
+		Request:   openapiUser{},
+		Responses: map[int]interface{}{201: &openapiUser{}},
+	})
+	doc.Document(r.HandleFunc("/users", ok).Methods(http.MethodGet), &Operation{
+		Query:      openapiListQuery{},
+		Deprecated: true,
+	})
+	r.HandleFunc("/undocumented", ok)
+	doc.Mount(r)
+	r.Use(doc.ValidationMiddleware())
+
+	return doc, r
+}
+
+// jsonPath navega no documento gerado usando chaves separadas por "/"; como
+// no JSON Pointer, "~1" representa uma "/" dentro da chave
+func jsonPath(t *testing.T, doc interface{}, path string) interface{} {
+	t.Helper()
+	cur := doc
+	for _, key := range strings.Split(path, "/") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: %q is not an object", path, key)
+		}
+		cur = m[strings.ReplaceAll(key, "~1", "/")]
+	}
+	return cur
+}
+
+func TestOpenAPIGenerate(t *testing.T) {
+	_, r := newTestOpenAPI(t, config.DEVELOPER)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DEFAULT_OPENAPI_PATH, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	userSchema := "components/schemas/httpserver.openapiUser"
+	tests := []struct {
+		path string
+		want interface{}
+	}{
+		{path: "openapi", want: OPENAPI_VERSION},
+		{path: "info/title", want: "users"},
+		{path: "info/version", want: "1.2.0"},
+		{path: "paths/~1users~1{id}/get/operationId", want: "getUser"},
+		{path: "paths/~1users/get/deprecated", want: true},
+		{path: "paths/~1users/post/requestBody/content/application~1json/schema/$ref", want: "#/components/schemas/httpserver.openapiUser"},
+		{path: userSchema + "/properties/id/format", want: "int64"},
+		{path: userSchema + "/properties/created_at/format", want: "date-time"},
+		{path: userSchema + "/properties/name/minLength", want: float64(3)},
+		{path: userSchema + "/properties/name/maxLength", want: float64(50)},
+		{path: userSchema + "/properties/tags/maxItems", want: float64(5)},
+		{path: userSchema + "/properties/meta/additionalProperties/type", want: "string"},
+		{path: userSchema + "/properties/manager/$ref", want: "#/components/schemas/httpserver.openapiUser"},
+		{path: userSchema + "/properties/Secret", want: nil},
+		{path: "components/schemas/Problem/type", want: "object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if cur := jsonPath(t, doc, tt.path); cur != tt.want {
+				t.Errorf("got %v, want %v", cur, tt.want)
+			}
+		})
+	}
+
+	required := jsonPath(t, doc, userSchema+"/required").([]interface{})
+	if len(required) != 1 || required[0] != "name" {
+		t.Errorf("required = %v", required)
+	}
+
+	params := jsonPath(t, doc, "paths/~1users/get/parameters").([]interface{})
+	names := map[string]bool{}
+	for _, p := range params {
+		param := p.(map[string]interface{})
+		names[param["name"].(string)] = param["required"].(bool)
+	}
+	if len(names) != 3 || !names["page"] || names["active"] || names["q"] {
+		t.Errorf("query params = %v", names)
+	}
+}
+
+func TestOpenAPIValidationMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{name: "valid body", mode: config.DEVELOPER, method: http.MethodPost, target: "/users", body: `{"name":"ana"}`, wantStatus: http.StatusNoContent},
+		{name: "unknown field", mode: config.DEVELOPER, method: http.MethodPost, target: "/users", body: `{"name":"ana","x":1}`, wantStatus: http.StatusBadRequest},
+		{name: "enum", mode: config.DEVELOPER, method: http.MethodPost, target: "/users", body: `{"name":"ana","role":"root"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "validate tags", mode: config.DEVELOPER, method: http.MethodPost, target: "/users", body: `{"name":"an"}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "empty body", mode: config.DEVELOPER, method: http.MethodPost, target: "/users", wantStatus: http.StatusBadRequest},
+		{name: "missing query", mode: config.DEVELOPER, method: http.MethodGet, target: "/users", wantStatus: http.StatusBadRequest},
+		{name: "invalid query type", mode: config.DEVELOPER, method: http.MethodGet, target: "/users?page=x", wantStatus: http.StatusBadRequest},
+		{name: "invalid bool query", mode: config.DEVELOPER, method: http.MethodGet, target: "/users?page=1&active=maybe", wantStatus: http.StatusBadRequest},
+		{name: "valid query", mode: config.DEVELOPER, method: http.MethodGet, target: "/users?page=1&active=true", wantStatus: http.StatusNoContent},
+		{name: "undocumented route", mode: config.DEVELOPER, method: http.MethodPost, target: "/undocumented", body: `{`, wantStatus: http.StatusNoContent},
+		{name: "production disabled", mode: config.PRODUCTION, method: http.MethodPost, target: "/users", body: `{"x":1}`, wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, r := newTestOpenAPI(t, tt.mode)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOpenAPIValidationKeepsBody(t *testing.T) {
+	doc := NewOpenAPI(&config.Config{AppMode: config.DEVELOPER, HttpConfig: &config.HttpConfig{}})
+
+	var got openapiUser
+	r := mux.NewRouter()
+	doc.Document(r.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		got, err = DecodeJSON[openapiUser](r, nil)
+		if err != nil {
+			WriteError(w, err)
+		}
+	}).Methods(http.MethodPost), &Operation{Request: openapiUser{}})
+	r.Use(doc.ValidationMiddleware())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"ana"}`)))
+
+	if w.Code != http.StatusOK || got.Name != "ana" {
+		t.Fatalf("status = %d, user = %+v", w.Code, got)
+	}
+}
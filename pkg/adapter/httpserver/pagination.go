@@ -0,0 +1,364 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_PAGE_LIMIT = 20
+	DEFAULT_MAX_LIMIT  = 100
+)
+
+// QueryOptions regras usadas pelo ParseQuery
+type QueryOptions struct {
+	// DefaultLimit itens por página quando ?limit= não é informado (padrão: 20)
+	DefaultLimit int
+	// MaxLimit maior ?limit= aceito (padrão: 100)
+	MaxLimit int
+	// Fields campos permitidos no sort e nos filtros; os demais são rejeitados
+	Fields map[string]query.Field
+	// DefaultSort sort usado quando ?sort= não é informado (ex: "-created_at")
+	DefaultSort string
+	// CursorField campo único adicionado ao fim do sort para desempate (ex:
+	// "id"). É adicionado em todas as páginas, com ou sem ?cursor=, para que a
+	// primeira página use a mesma ordem das seguintes. Precisa estar em Fields
+	// com Sortable; caso contrário o ParseQuery retorna erro 500.
+	CursorField string
+}
+
+// Page envelope padrão das respostas paginadas
+type Page[T any] struct {
+	Data  []T       `json:"data"`
+	Meta  PageMeta  `json:"meta"`
+	Links PageLinks `json:"links"`
+}
+
+type PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// ParseQuery lê paginação, ordenação e filtros da query string:
+//
+//	?page=2&limit=20                 paginação por offset
+//	?cursor=<next_cursor>&limit=20   paginação por cursor
+//	?before=<prev_cursor>&limit=20   página anterior na paginação por cursor
+//	?sort=-created_at,name           "-" indica ordem decrescente
+//	?status=active                   filtro de igualdade
+//	?age[gte]=18&role[in]=admin,user filtros com operador (eq, ne, gt, gte, lt, lte, in, like)
+//
+// Campos fora de opts.Fields, operadores não permitidos e valores inválidos
+// retornam um *HttpMsg 400 com o parâmetro em Field.
+//
+// Exemplo de Uso:
+//
+//	q, err := httpserver.ParseQuery(r, &httpserver.QueryOptions{
+//	    Fields: map[string]query.Field{
+//	        "name":       {Sortable: true, Filterable: true},
+//	        "age":        {Type: query.TYPE_INT, Filterable: true},
+//	        "created_at": {Type: query.TYPE_TIME, Sortable: true},
+//	        "id":         {Type: query.TYPE_INT, Sortable: true},
+//	    },
+//	    DefaultSort: "-created_at",
+//	    CursorField: "id",
+//	})
+//	if err != nil {
+//	    httpserver.WriteProblem(w, r, err)
+//	    return
+//	}
+func ParseQuery(r *http.Request, opts *QueryOptions) (*query.Query, error) {
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+
+	defaultLimit := opts.DefaultLimit
+	if defaultLimit <= 0 {
+		defaultLimit = DEFAULT_PAGE_LIMIT
+	}
+	maxLimit := opts.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = DEFAULT_MAX_LIMIT
+	}
+
+	values := r.URL.Query()
+	q := &query.Query{Page: 1, Limit: defaultLimit}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return nil, invalidQueryParam("limit")
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, invalidQueryParam("page")
+		}
+		q.Page = page
+	}
+
+	sortParam := values.Get("sort")
+	if sortParam == "" {
+		sortParam = opts.DefaultSort
+	}
+	for _, item := range strings.Split(sortParam, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(item, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(item, "-"), "+")
+
+		field, ok := opts.Fields[name]
+		if !ok || !field.Sortable {
+			return nil, invalidQueryParam("sort")
+		}
+		q.Sort = append(q.Sort, query.Sort{Field: name, Column: columnOf(name, field), Desc: desc})
+	}
+
+	if opts.CursorField != "" {
+		// Sem essa checagem os tradutores ordenariam por uma coluna fora da lista permitida
+		if field, ok := opts.Fields[opts.CursorField]; !ok || !field.Sortable {
+			log.Error().Str("FunctionName", "ParseQuery").Str("CursorField", opts.CursorField).Msg("CursorField is not a sortable field of QueryOptions.Fields")
+			msg := ErroHttpMsgInternalServerError
+			return nil, &msg
+		}
+
+		hasCursorField := false
+		for _, s := range q.Sort {
+			if s.Field == opts.CursorField {
+				hasCursorField = true
+			}
+		}
+		if !hasCursorField {
+			field := opts.Fields[opts.CursorField]
+			q.Sort = append(q.Sort, query.Sort{Field: opts.CursorField, Column: columnOf(opts.CursorField, field)})
+		}
+	}
+
+	cursor, before := values.Get("cursor"), values.Get("before")
+	switch {
+	case cursor != "" && before != "":
+		return nil, invalidQueryParam("before")
+	case cursor != "":
+		if err := parseCursor(q, "cursor", cursor, opts); err != nil {
+			return nil, err
+		}
+	case before != "":
+		if err := parseCursor(q, "before", before, opts); err != nil {
+			return nil, err
+		}
+		q.Before = true
+	}
+
+	// Chaves ordenadas para gerar sempre o mesmo SQL para a mesma URL
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		vals := values[key]
+		name, op := key, query.OP_EQ
+		if idx := strings.IndexByte(key, '['); idx > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:idx], key[idx+1:len(key)-1]
+		}
+
+		switch name {
+		case "page", "limit", "sort", "cursor", "before":
+			continue
+		}
+
+		field, ok := opts.Fields[name]
+		if !ok || !field.Filterable || !operatorAllowed(op, field) {
+			return nil, invalidQueryParam(key)
+		}
+
+		filter := query.Filter{Field: name, Column: columnOf(name, field), Op: op}
+
+		if op == query.OP_IN {
+			for _, raw := range strings.Split(vals[0], ",") {
+				v, err := query.ParseValue(strings.TrimSpace(raw), field.Type)
+				if err != nil {
+					return nil, invalidQueryParam(key)
+				}
+				filter.Values = append(filter.Values, v)
+			}
+		} else {
+			v, err := query.ParseValue(vals[0], field.Type)
+			if err != nil {
+				return nil, invalidQueryParam(key)
+			}
+			filter.Value = v
+		}
+
+		q.Filters = append(q.Filters, filter)
+	}
+
+	return q, nil
+}
+
+func parseCursor(q *query.Query, param, cursor string, opts *QueryOptions) error {
+	// O CursorField já foi adicionado ao sort pelo ParseQuery
+	if opts.CursorField == "" {
+		return invalidQueryParam(param)
+	}
+
+	types := make([]string, len(q.Sort))
+	for i, s := range q.Sort {
+		types[i] = opts.Fields[s.Field].Type
+	}
+
+	after, err := query.DecodeCursor(cursor, types)
+	if err != nil {
+		return invalidQueryParam(param)
+	}
+
+	q.Cursor = cursor
+	q.After = after
+	q.Page = 0
+
+	return nil
+}
+
+func columnOf(name string, field query.Field) string {
+	if field.Column != "" {
+		return field.Column
+	}
+	return name
+}
+
+func operatorAllowed(op string, field query.Field) bool {
+	switch op {
+	case query.OP_EQ, query.OP_NE, query.OP_GT, query.OP_GTE, query.OP_LT, query.OP_LTE, query.OP_IN, query.OP_LIKE:
+	default:
+		return false
+	}
+
+	// like só faz sentido em texto; nos demais tipos o valor convertido não é
+	// string e o ILIKE/regex não filtraria nada
+	if op == query.OP_LIKE && field.Type != "" && field.Type != query.TYPE_STRING {
+		return false
+	}
+
+	if len(field.Operators) == 0 {
+		return true
+	}
+	for _, allowed := range field.Operators {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidQueryParam(name string) *HttpMsg {
+	return &HttpMsg{Msg: "Erro Invalid Query Parameter", Code: http.StatusBadRequest, Field: name}
+}
+
+// NewPage monta o envelope da paginação por offset. Com total negativo (total
+// desconhecido) o link next é gerado quando a página veio cheia.
+func NewPage[T any](r *http.Request, q *query.Query, data []T, total int64) *Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	page := &Page[T]{
+		Data:  data,
+		Meta:  PageMeta{Page: q.Page, Limit: q.Limit},
+		Links: PageLinks{Self: pageLink(r, map[string]string{})},
+	}
+
+	hasNext := len(data) == q.Limit
+	if total >= 0 {
+		page.Meta.Total = &total
+		hasNext = int64(q.Page*q.Limit) < total
+	}
+
+	if hasNext {
+		page.Links.Next = pageLink(r, map[string]string{"page": strconv.Itoa(q.Page + 1), "cursor": "", "before": ""})
+	}
+	if q.Page > 1 {
+		page.Links.Prev = pageLink(r, map[string]string{"page": strconv.Itoa(q.Page - 1), "cursor": "", "before": ""})
+	}
+
+	return page
+}
+
+// NewCursorPage monta o envelope da paginação por cursor. nextCursor é gerado
+// a partir do último item de data e prevCursor do primeiro (ver
+// query.EncodeCursor); vazio indica que não há próxima/anterior página. data
+// precisa estar na ordem do Sort, ou seja, já revertido quando q.Before.
+//
+// Exemplo de Uso:
+//
+//	// Busca q.Limit+1 itens para saber se existe mais uma página na direção pedida
+//	users, more := items[:min(len(items), q.Limit)], len(items) > q.Limit
+//	hasNext, hasPrev := more, q.IsCursor()
+//	if q.Before {
+//	    slices.Reverse(users)
+//	    hasNext, hasPrev = true, more
+//	}
+//	var next, prev string
+//	if len(users) > 0 && hasNext {
+//	    next = query.EncodeCursor(users[len(users)-1].CreatedAt, users[len(users)-1].ID)
+//	}
+//	if len(users) > 0 && hasPrev {
+//	    prev = query.EncodeCursor(users[0].CreatedAt, users[0].ID)
+//	}
+//	httpserver.WriteJSON(w, http.StatusOK, httpserver.NewCursorPage(r, q, users, next, prev))
+func NewCursorPage[T any](r *http.Request, q *query.Query, data []T, nextCursor, prevCursor string) *Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	page := &Page[T]{
+		Data:  data,
+		Meta:  PageMeta{Limit: q.Limit, NextCursor: nextCursor, PrevCursor: prevCursor},
+		Links: PageLinks{Self: pageLink(r, map[string]string{})},
+	}
+
+	if nextCursor != "" {
+		page.Links.Next = pageLink(r, map[string]string{"cursor": nextCursor, "before": "", "page": ""})
+	}
+	if prevCursor != "" {
+		page.Links.Prev = pageLink(r, map[string]string{"before": prevCursor, "cursor": "", "page": ""})
+	}
+
+	return page
+}
+
+// pageLink copia a URL da requisição trocando os parâmetros informados (valor vazio remove)
+func pageLink(r *http.Request, params map[string]string) string {
+	values := r.URL.Query()
+	for k, v := range params {
+		if v == "" {
+			values.Del(k)
+		} else {
+			values.Set(k, v)
+		}
+	}
+
+	u := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+	return u.String()
+}
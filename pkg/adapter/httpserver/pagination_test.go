@@ -0,0 +1,243 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+)
+
+var paginationTestOptions = &QueryOptions{
+	Fields: map[string]query.Field{
+		"name":       {Sortable: true, Filterable: true},
+		"age":        {Type: query.TYPE_INT, Filterable: true},
+		"active":     {Type: query.TYPE_BOOL, Filterable: true, Operators: []string{query.OP_EQ}},
+		"created_at": {Column: "u.created_at", Type: query.TYPE_TIME, Sortable: true},
+		"id":         {Type: query.TYPE_INT, Sortable: true},
+	},
+	DefaultSort: "-created_at",
+	CursorField: "id",
+	MaxLimit:    50,
+}
+
+func TestParseQuery(t *testing.T) {
+	cursor := query.EncodeCursor("2026-01-02T03:04:05Z", 7)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantPage   int
+		wantLimit  int
+		wantSort   []query.Sort
+		wantFilter []query.Filter
+		wantAfter  int
+		wantBefore bool
+	}{
+		{
+			name:      "defaults add cursor field",
+			target:    "/users",
+			wantPage:  1,
+			wantLimit: DEFAULT_PAGE_LIMIT,
+			wantSort:  []query.Sort{{Field: "created_at", Column: "u.created_at", Desc: true}, {Field: "id", Column: "id"}},
+		},
+		{
+			name:      "explicit sort keeps cursor field",
+			target:    "/users?sort=name,-id&page=2&limit=50",
+			wantPage:  2,
+			wantLimit: 50,
+			wantSort:  []query.Sort{{Field: "name", Column: "name"}, {Field: "id", Column: "id", Desc: true}},
+		},
+		{
+			name:      "cursor",
+			target:    "/users?cursor=" + cursor,
+			wantLimit: DEFAULT_PAGE_LIMIT,
+			wantSort:  []query.Sort{{Field: "created_at", Column: "u.created_at", Desc: true}, {Field: "id", Column: "id"}},
+			wantAfter: 2,
+		},
+		{
+			name:       "before",
+			target:     "/users?before=" + cursor,
+			wantLimit:  DEFAULT_PAGE_LIMIT,
+			wantSort:   []query.Sort{{Field: "created_at", Column: "u.created_at", Desc: true}, {Field: "id", Column: "id"}},
+			wantAfter:  2,
+			wantBefore: true,
+		},
+		{
+			name:      "filters",
+			target:    "/users?name=ana&age[gte]=18&age[in]=1,2&name[like]=an",
+			wantPage:  1,
+			wantLimit: DEFAULT_PAGE_LIMIT,
+			wantSort:  []query.Sort{{Field: "created_at", Column: "u.created_at", Desc: true}, {Field: "id", Column: "id"}},
+			wantFilter: []query.Filter{
+				{Field: "age", Column: "age", Op: query.OP_GTE, Value: int64(18)},
+				{Field: "age", Column: "age", Op: query.OP_IN, Values: []interface{}{int64(1), int64(2)}},
+				{Field: "name", Column: "name", Op: query.OP_EQ, Value: "ana"},
+				{Field: "name", Column: "name", Op: query.OP_LIKE, Value: "an"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(httptest.NewRequest(http.MethodGet, tt.target, nil), paginationTestOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if q.Page != tt.wantPage || q.Limit != tt.wantLimit {
+				t.Errorf("page = %d, limit = %d, want %d, %d", q.Page, q.Limit, tt.wantPage, tt.wantLimit)
+			}
+			if len(q.Sort) != len(tt.wantSort) {
+				t.Fatalf("sort = %+v, want %+v", q.Sort, tt.wantSort)
+			}
+			for i := range q.Sort {
+				if q.Sort[i] != tt.wantSort[i] {
+					t.Errorf("sort[%d] = %+v, want %+v", i, q.Sort[i], tt.wantSort[i])
+				}
+			}
+			if len(q.After) != tt.wantAfter || q.Before != tt.wantBefore {
+				t.Errorf("after = %v, before = %v, want %d values, %v", q.After, q.Before, tt.wantAfter, tt.wantBefore)
+			}
+			if len(q.Filters) != len(tt.wantFilter) {
+				t.Fatalf("filters = %+v, want %+v", q.Filters, tt.wantFilter)
+			}
+			for i, f := range q.Filters {
+				want := tt.wantFilter[i]
+				if f.Field != want.Field || f.Column != want.Column || f.Op != want.Op || f.Value != want.Value || len(f.Values) != len(want.Values) {
+					t.Errorf("filters[%d] = %+v, want %+v", i, f, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		opts      *QueryOptions
+		wantCode  int
+		wantField string
+	}{
+		{name: "limit too large", target: "/?limit=51", wantField: "limit"},
+		{name: "limit zero", target: "/?limit=0", wantField: "limit"},
+		{name: "invalid page", target: "/?page=x", wantField: "page"},
+		{name: "sort unknown field", target: "/?sort=password", wantField: "sort"},
+		{name: "sort not sortable", target: "/?sort=age", wantField: "sort"},
+		{name: "filter unknown field", target: "/?password=x", wantField: "password"},
+		{name: "filter not filterable", target: "/?id=1", wantField: "id"},
+		{name: "unknown operator", target: "/?age[regex]=1", wantField: "age[regex]"},
+		{name: "operator not allowed", target: "/?active[ne]=true", wantField: "active[ne]"},
+		{name: "like on int", target: "/?age[like]=1", wantField: "age[like]"},
+		{name: "invalid value", target: "/?age=x", wantField: "age"},
+		{name: "invalid in value", target: "/?age[in]=1,x", wantField: "age[in]"},
+		{name: "invalid cursor", target: "/?cursor=abc", wantField: "cursor"},
+		{name: "cursor without field", target: "/?cursor=abc", opts: &QueryOptions{}, wantField: "cursor"},
+		{name: "invalid before", target: "/?before=abc", wantField: "before"},
+		{name: "cursor and before", target: "/?cursor=abc&before=abc", wantField: "before"},
+		{
+			name:     "cursor field not allowed",
+			target:   "/",
+			opts:     &QueryOptions{Fields: map[string]query.Field{"name": {Sortable: true}}, CursorField: "password"},
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "cursor field not sortable",
+			target:   "/",
+			opts:     &QueryOptions{Fields: map[string]query.Field{"id": {Filterable: true}}, CursorField: "id"},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts == nil {
+				opts = paginationTestOptions
+			}
+
+			_, err := ParseQuery(httptest.NewRequest(http.MethodGet, tt.target, nil), opts)
+
+			var msg *HttpMsg
+			if !errors.As(err, &msg) {
+				t.Fatalf("err = %v, want *HttpMsg", err)
+			}
+			wantCode := tt.wantCode
+			if wantCode == 0 {
+				wantCode = http.StatusBadRequest
+			}
+			if msg.Code != wantCode || msg.Field != tt.wantField {
+				t.Errorf("code = %d, field = %q, want %d, %q", msg.Code, msg.Field, wantCode, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		q        *query.Query
+		items    int
+		total    int64
+		wantNext string
+		wantPrev string
+	}{
+		{name: "first page", target: "/users?limit=2", q: &query.Query{Page: 1, Limit: 2}, items: 2, total: 5, wantNext: "/users?limit=2&page=2"},
+		{name: "middle page", target: "/users?page=2&limit=2", q: &query.Query{Page: 2, Limit: 2}, items: 2, total: 5, wantNext: "/users?limit=2&page=3", wantPrev: "/users?limit=2&page=1"},
+		{name: "last page", target: "/users?page=3&limit=2", q: &query.Query{Page: 3, Limit: 2}, items: 1, total: 5, wantPrev: "/users?limit=2&page=2"},
+		{name: "unknown total full page", target: "/users?limit=2", q: &query.Query{Page: 1, Limit: 2}, items: 2, total: -1, wantNext: "/users?limit=2&page=2"},
+		{name: "unknown total partial page", target: "/users?limit=2", q: &query.Query{Page: 1, Limit: 2}, items: 1, total: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := NewPage(httptest.NewRequest(http.MethodGet, tt.target, nil), tt.q, make([]int, tt.items), tt.total)
+
+			if page.Links.Next != tt.wantNext {
+				t.Errorf("next = %q, want %q", page.Links.Next, tt.wantNext)
+			}
+			if page.Links.Prev != tt.wantPrev {
+				t.Errorf("prev = %q, want %q", page.Links.Prev, tt.wantPrev)
+			}
+			if (tt.total >= 0) != (page.Meta.Total != nil) {
+				t.Errorf("total = %v", page.Meta.Total)
+			}
+		})
+	}
+}
+
+func TestNewCursorPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		next     string
+		prev     string
+		wantNext string
+		wantPrev string
+	}{
+		{name: "first page", target: "/users?page=2&limit=2", next: "abc", wantNext: "/users?cursor=abc&limit=2"},
+		{name: "middle page", target: "/users?cursor=abc&limit=2", next: "def", prev: "bcd", wantNext: "/users?cursor=def&limit=2", wantPrev: "/users?before=bcd&limit=2"},
+		{name: "page before", target: "/users?before=bcd&limit=2", next: "abc", prev: "aaa", wantNext: "/users?cursor=abc&limit=2", wantPrev: "/users?before=aaa&limit=2"},
+		{name: "last page", target: "/users?cursor=def&limit=2", prev: "efg", wantPrev: "/users?before=efg&limit=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			page := NewCursorPage[int](r, &query.Query{Limit: 2}, nil, tt.next, tt.prev)
+
+			if page.Data == nil || len(page.Data) != 0 {
+				t.Errorf("data = %v, want empty slice", page.Data)
+			}
+			if page.Links.Next != tt.wantNext || page.Meta.NextCursor != tt.next {
+				t.Errorf("next = %q, cursor = %q, want %q", page.Links.Next, page.Meta.NextCursor, tt.wantNext)
+			}
+			if page.Links.Prev != tt.wantPrev || page.Meta.PrevCursor != tt.prev {
+				t.Errorf("prev = %q, cursor = %q, want %q", page.Links.Prev, page.Meta.PrevCursor, tt.wantPrev)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+	"github.com/phuslu/log"
+)
+
+// CONTENT_TYPE_PROBLEM_JSON valor do header Content-Type definido pela RFC 7807
+const CONTENT_TYPE_PROBLEM_JSON = "application/problem+json; charset=utf-8"
+
+const (
+	// ERROR_MODE_PROBLEM as respostas de erro padrão usam application/problem+json
+	ERROR_MODE_PROBLEM = "problem"
+	// ERROR_MODE_HTTPMSG as respostas de erro padrão usam o formato legado HttpMsg
+	ERROR_MODE_HTTPMSG = "httpmsg"
+)
+
+const PROBLEM_TYPE_DEFAULT = "about:blank"
+
+// Problem modelo de erro da RFC 7807 (application/problem+json).
+//
+// Os campos em Extensions são serializados no mesmo nível dos campos padrão.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem cria um Problem com o título padrão do status
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   PROBLEM_TYPE_DEFAULT,
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With adiciona um campo de extensão ao Problem
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	if p.Title != "" {
+		return p.Title
+	}
+	return http.StatusText(p.Status)
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	out["type"] = p.Type
+	out["title"] = p.Title
+	out["status"] = p.Status
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+
+	return json.Marshal(out)
+}
+
+// Write escreve o Problem na resposta. Quando Title não foi informado ele é
+// traduzido de acordo com o header Accept-Language e Instance recebe o path
+// da requisição.
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	out := *p
+
+	if out.Type == "" {
+		out.Type = PROBLEM_TYPE_DEFAULT
+	}
+
+	if out.Status == 0 {
+		out.Status = http.StatusInternalServerError
+	}
+
+	acceptLanguage := ""
+	if r != nil {
+		acceptLanguage = r.Header.Get("Accept-Language")
+		if out.Instance == "" {
+			out.Instance = r.URL.Path
+		}
+	}
+
+	if out.Title == "" {
+		out.Title = ProblemTitle(out.Status, acceptLanguage)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		log.Error().Str("FunctionName", "Problem.Write").Msg(err.Error())
+	}
+
+	w.Header().Set("Content-Type", CONTENT_TYPE_PROBLEM_JSON)
+	w.WriteHeader(out.Status)
+	w.Write(data)
+}
+
+// ProblemFromError converte um erro em Problem usando errors.As.
+//
+// São reconhecidos *Problem, *HttpMsg e validator.ValidationErrors; qualquer
+// outro erro vira um 500 sem expor a mensagem interna.
+func ProblemFromError(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var msg *HttpMsg
+	if errors.As(err, &msg) {
+		p := NewProblem(msg.Code, msg.Msg)
+		if msg.Field != "" {
+			p.With("field", msg.Field)
+		}
+		if len(msg.Errors) > 0 {
+			p.With("errors", msg.Errors)
+		}
+		return p
+	}
+
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		return NewProblem(http.StatusUnprocessableEntity, ErroHttpMsgValidationFailed.Msg).With("errors", verrs)
+	}
+
+	log.Error().Str("FunctionName", "ProblemFromError").Msg(err.Error())
+	return NewProblem(http.StatusInternalServerError, "")
+}
+
+// WriteProblem converte o erro com ProblemFromError e escreve a resposta
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	ProblemFromError(err).Write(w, r)
+}
+
+var (
+	problemTitlesLock sync.RWMutex
+	problemTitles     = map[string]map[int]string{
+		"pt": {
+			http.StatusBadRequest:            "Requisição Inválida",
+			http.StatusUnauthorized:          "Não Autorizado",
+			http.StatusForbidden:             "Acesso Negado",
+			http.StatusNotFound:              "Página Não Encontrada",
+			http.StatusMethodNotAllowed:      "Método Não Permitido",
+			http.StatusConflict:              "Conflito",
+			http.StatusRequestEntityTooLarge: "Corpo da Requisição Muito Grande",
+			http.StatusUnprocessableEntity:   "Entidade Não Processável",
+			http.StatusTooManyRequests:       "Muitas Requisições",
+			http.StatusInternalServerError:   "Erro Interno do Servidor",
+			http.StatusServiceUnavailable:    "Serviço Indisponível",
+		},
+	}
+)
+
+// RegisterProblemTitles registra (ou sobrescreve) traduções de títulos para
+// um idioma. O idioma segue o Accept-Language, ex: "pt", "pt-BR", "es".
+func RegisterProblemTitles(lang string, titles map[int]string) {
+	problemTitlesLock.Lock()
+	defer problemTitlesLock.Unlock()
+
+	lang = strings.ToLower(lang)
+	if problemTitles[lang] == nil {
+		problemTitles[lang] = map[int]string{}
+	}
+	for status, title := range titles {
+		problemTitles[lang][status] = title
+	}
+}
+
+// ProblemTitle retorna o título do status no idioma preferido do
+// Accept-Language, usando o texto padrão em inglês quando não há tradução.
+func ProblemTitle(status int, acceptLanguage string) string {
+	problemTitlesLock.RLock()
+	defer problemTitlesLock.RUnlock()
+
+	for _, lang := range parseAcceptLanguage(acceptLanguage) {
+		if title, ok := problemTitles[lang][status]; ok {
+			return title
+		}
+		base, _, found := strings.Cut(lang, "-")
+		if found {
+			if title, ok := problemTitles[base][status]; ok {
+				return title
+			}
+		}
+		// Inglês é o idioma do http.StatusText, não precisa de tradução
+		if base == "en" {
+			break
+		}
+	}
+
+	return http.StatusText(status)
+}
+
+func parseAcceptLanguage(header string) []string {
+	type langQ struct {
+		lang string
+		q    float64
+	}
+
+	var langs []langQ
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lang, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		// q=0 significa que o idioma não é aceito
+		if q <= 0 {
+			continue
+		}
+
+		langs = append(langs, langQ{lang: strings.ToLower(strings.TrimSpace(lang)), q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, 0, len(langs))
+	for _, l := range langs {
+		result = append(result, l.lang)
+	}
+
+	return result
+}
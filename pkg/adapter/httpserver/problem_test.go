@@ -0,0 +1,145 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+)
+
+func TestProblemTitle(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "empty", acceptLanguage: "", want: "Not Found"},
+		{name: "pt", acceptLanguage: "pt", want: "Página Não Encontrada"},
+		{name: "pt-BR uses base", acceptLanguage: "pt-BR", want: "Página Não Encontrada"},
+		{name: "unknown falls back", acceptLanguage: "de", want: "Not Found"},
+		{name: "unknown then pt", acceptLanguage: "de, pt;q=0.5", want: "Página Não Encontrada"},
+		{name: "en preferred over pt", acceptLanguage: "en, pt-BR;q=0.5", want: "Not Found"},
+		{name: "en-US preferred over pt", acceptLanguage: "en-US, pt;q=0.5", want: "Not Found"},
+		{name: "q orders", acceptLanguage: "en;q=0.3, pt-BR;q=0.8", want: "Página Não Encontrada"},
+		{name: "q=0 excluded", acceptLanguage: "pt;q=0, de", want: "Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProblemTitle(http.StatusNotFound, tt.acceptLanguage); got != tt.want {
+				t.Errorf("ProblemTitle(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProblemTitles(t *testing.T) {
+	RegisterProblemTitles("ES", map[int]string{http.StatusTeapot: "Soy una tetera"})
+	if got := ProblemTitle(http.StatusTeapot, "es-AR"); got != "Soy una tetera" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestProblemWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	r.Header.Set("Accept-Language", "pt-BR")
+	w := httptest.NewRecorder()
+
+	NewProblem(http.StatusConflict, "duplicado").With("field", "email").Write(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != CONTENT_TYPE_PROBLEM_JSON {
+		t.Errorf("content-type = %q", ct)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":     PROBLEM_TYPE_DEFAULT,
+		"title":    "Conflito",
+		"status":   float64(http.StatusConflict),
+		"detail":   "duplicado",
+		"instance": "/users/1",
+		"field":    "email",
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	msg := ErroHttpMsgRequestBodyTooLarge
+	msg.Field = "file"
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+		wantExt    string
+	}{
+		{name: "problem", err: NewProblem(http.StatusForbidden, "sem acesso"), wantStatus: http.StatusForbidden, wantDetail: "sem acesso"},
+		{name: "wrapped problem", err: fmt.Errorf("x: %w", NewProblem(http.StatusConflict, "c")), wantStatus: http.StatusConflict, wantDetail: "c"},
+		{name: "httpmsg", err: &msg, wantStatus: http.StatusRequestEntityTooLarge, wantDetail: msg.Msg, wantExt: "field"},
+		{
+			name:       "validation",
+			err:        validator.ValidationErrors{{Field: "name", Rule: "required"}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantDetail: ErroHttpMsgValidationFailed.Msg,
+			wantExt:    "errors",
+		},
+		{name: "internal", err: errors.New("db down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ProblemFromError(tt.err)
+			if p.Status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", p.Status, tt.wantStatus)
+			}
+			if p.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", p.Detail, tt.wantDetail)
+			}
+			if tt.wantExt != "" && p.Extensions[tt.wantExt] == nil {
+				t.Errorf("extension %q missing: %v", tt.wantExt, p.Extensions)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorHandlers(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.Handler
+		wantStatus  int
+		contentType string
+	}{
+		{name: "not found problem", handler: defaultNotFoundHandler(ERROR_MODE_PROBLEM), wantStatus: http.StatusNotFound, contentType: CONTENT_TYPE_PROBLEM_JSON},
+		{name: "not found httpmsg", handler: defaultNotFoundHandler(ERROR_MODE_HTTPMSG), wantStatus: http.StatusNotFound, contentType: CONTENT_TYPE_JSON},
+		{name: "method problem", handler: defaultMethodNotAllowedHandler(ERROR_MODE_PROBLEM), wantStatus: http.StatusMethodNotAllowed, contentType: CONTENT_TYPE_PROBLEM_JSON},
+		{name: "method httpmsg", handler: defaultMethodNotAllowedHandler(ERROR_MODE_HTTPMSG), wantStatus: http.StatusMethodNotAllowed, contentType: CONTENT_TYPE_JSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("content-type = %q, want %q", ct, tt.contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/phuslu/log"
+)
+
+const (
+	// VERSION_STRATEGY_PATH a versão faz parte do path (ex: /api/v2/users)
+	VERSION_STRATEGY_PATH = "path"
+	// VERSION_STRATEGY_HEADER a versão é enviada no header VersionConfig.Header (ex: Api-Version: v2)
+	VERSION_STRATEGY_HEADER = "header"
+	// VERSION_STRATEGY_MEDIA_TYPE a versão é enviada no Accept (ex: application/vnd.myapp.v2+json)
+	VERSION_STRATEGY_MEDIA_TYPE = "media-type"
+
+	DEFAULT_VERSION_HEADER = "Api-Version"
+)
+
+// VersionConfig configuração de uma versão da API
+type VersionConfig struct {
+	// Strategy como a versão é identificada (padrão: VERSION_STRATEGY_PATH)
+	Strategy string
+	// Header nome do header na estratégia header (padrão: Api-Version)
+	Header string
+	// Vendor nome usado no media type application/vnd.<Vendor>.<versão>+json
+	Vendor string
+	// Default atende as requisições sem versão nas estratégias header e media-type
+	Default bool
+	// Deprecated adiciona o header Deprecation nas respostas desta versão
+	Deprecated bool
+	// DeprecatedAt data da depreciação; quando vazio o header é enviado como "true"
+	DeprecatedAt time.Time
+	// Sunset data em que a versão deixará de funcionar (header Sunset)
+	Sunset time.Time
+	// Link documentação ou versão sucessora (header Link com rel="successor-version")
+	Link string
+}
+
+// RouteGroup agrupa rotas com o mesmo prefixo, versão e middlewares
+type RouteGroup struct {
+	router *mux.Router
+}
+
+// RouteInfo rota registrada no router
+type RouteInfo struct {
+	Methods []string `json:"methods"`
+	Path    string   `json:"path"`
+	Name    string   `json:"name,omitempty"`
+	Version string   `json:"version,omitempty"`
+}
+
+// versionRoutes guarda a versão das rotas criadas pelo Version para o DumpRoutes
+var versionRoutes sync.Map // *mux.Route -> string
+
+// NewRouteGroup cria um grupo a partir do router passado ao httpserver.New.
+//
+// Exemplo de Uso:
+//
+//	api := httpserver.NewRouteGroup(router, "/api", httpserver.ContentTypeJSONMiddleware)
+//
+//	v1 := api.Version("v1", &httpserver.VersionConfig{
+//	    Deprecated: true,
+//	    Sunset:     time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+//	    Link:       "/api/v2",
+//	})
+//	v1.HandleFunc("/users", listUsersV1).Methods("GET")
+//
+//	v2 := api.Version("v2", nil)
+//	admin := v2.Group("/admin", authMiddleware)
+//	admin.HandleFunc("/users/{id}", deleteUser).Methods("DELETE")
+//
+//	httpserver.LogRoutes(router)
+func NewRouteGroup(r *mux.Router, prefix string, middlewares ...mux.MiddlewareFunc) *RouteGroup {
+	g := &RouteGroup{router: r}
+	return g.Group(prefix, middlewares...)
+}
+
+// Group cria um subgrupo com o prefixo informado. Os middlewares são aplicados
+// apenas nas rotas do subgrupo, depois dos middlewares dos grupos pais.
+func (g *RouteGroup) Group(prefix string, middlewares ...mux.MiddlewareFunc) *RouteGroup {
+	router := g.router
+	if prefix != "" && prefix != "/" {
+		router = router.PathPrefix("/" + strings.Trim(prefix, "/")).Subrouter()
+	} else {
+		router = router.NewRoute().Subrouter()
+	}
+	router.Use(middlewares...)
+
+	return &RouteGroup{router: router}
+}
+
+// Version cria um subgrupo para a versão informada (ex: "v1"). Com cfg nil a
+// versão usa a estratégia path e não é depreciada.
+func (g *RouteGroup) Version(version string, cfg *VersionConfig, middlewares ...mux.MiddlewareFunc) *RouteGroup {
+	if cfg == nil {
+		cfg = &VersionConfig{}
+	}
+
+	var route *mux.Route
+	switch cfg.Strategy {
+	case VERSION_STRATEGY_HEADER:
+		header := cfg.Header
+		if header == "" {
+			header = DEFAULT_VERSION_HEADER
+		}
+		route = g.router.MatcherFunc(func(r *http.Request, rm *mux.RouteMatch) bool {
+			requested := r.Header.Get(header)
+			return strings.EqualFold(requested, version) || (requested == "" && cfg.Default)
+		})
+	case VERSION_STRATEGY_MEDIA_TYPE:
+		vendor := "application/vnd." + strings.ToLower(cfg.Vendor) + "."
+		route = g.router.MatcherFunc(func(r *http.Request, rm *mux.RouteMatch) bool {
+			requested := acceptedVersion(r.Header.Get("Accept"), vendor)
+			return strings.EqualFold(requested, version) || (requested == "" && cfg.Default)
+		})
+	default:
+		route = g.router.PathPrefix("/" + strings.Trim(version, "/"))
+	}
+
+	versionRoutes.Store(route, version)
+
+	router := route.Subrouter()
+	if cfg.Deprecated || !cfg.Sunset.IsZero() {
+		router.Use(deprecationMiddleware(cfg))
+	}
+	router.Use(middlewares...)
+
+	return &RouteGroup{router: router}
+}
+
+// Use adiciona middlewares ao grupo (autenticação, rate limit, cache, etc)
+func (g *RouteGroup) Use(middlewares ...mux.MiddlewareFunc) {
+	g.router.Use(middlewares...)
+}
+
+// Router retorna o *mux.Router do grupo
+func (g *RouteGroup) Router() *mux.Router {
+	return g.router
+}
+
+func (g *RouteGroup) Handle(path string, handler http.Handler) *mux.Route {
+	return g.router.Handle(path, handler)
+}
+
+func (g *RouteGroup) HandleFunc(path string, f func(http.ResponseWriter, *http.Request)) *mux.Route {
+	return g.router.HandleFunc(path, f)
+}
+
+// deprecationMiddleware adiciona os headers Deprecation (RFC 9745), Sunset (RFC 8594) e Link
+func deprecationMiddleware(cfg *VersionConfig) mux.MiddlewareFunc {
+	deprecation := ""
+	if cfg.Deprecated {
+		deprecation = "true"
+		if !cfg.DeprecatedAt.IsZero() {
+			deprecation = "@" + strconv.FormatInt(cfg.DeprecatedAt.Unix(), 10)
+		}
+	}
+
+	sunset := ""
+	if !cfg.Sunset.IsZero() {
+		sunset = cfg.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	link := ""
+	if cfg.Link != "" {
+		link = "<" + cfg.Link + `>; rel="successor-version"`
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			if deprecation != "" {
+				h.Set("Deprecation", deprecation)
+			}
+			if sunset != "" {
+				h.Set("Sunset", sunset)
+			}
+			if link != "" {
+				h.Add("Link", link)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptedVersion extrai a versão de um Accept como application/vnd.myapp.v2+json
+func acceptedVersion(accept, vendor string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		if version, found := strings.CutPrefix(mediaType, vendor); found {
+			version, _, _ = strings.Cut(version, "+")
+			return version
+		}
+	}
+	return ""
+}
+
+// DumpRoutes lista as rotas registradas no router
+func DumpRoutes(r *mux.Router) []RouteInfo {
+	var routes []RouteInfo
+
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		// Rotas sem handler são os PathPrefix/MatcherFunc dos grupos
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		info := RouteInfo{Name: route.GetName()}
+
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			info.Path = tpl
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			info.Methods = methods
+		}
+
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			if version, ok := versionRoutes.Load(ancestors[i]); ok {
+				info.Version = version.(string)
+				break
+			}
+		}
+
+		routes = append(routes, info)
+		return nil
+	})
+
+	return routes
+}
+
+// LogRoutes registra no log as rotas do router, útil na inicialização do serviço
+func LogRoutes(r *mux.Router) {
+	for _, route := range DumpRoutes(r) {
+		methods := "ANY"
+		if len(route.Methods) > 0 {
+			methods = strings.Join(route.Methods, ",")
+		}
+
+		log.Info().Str("Methods", methods).Str("Path", route.Path).Str("Name", route.Name).Str("Version", route.Version).Msg("Route")
+	}
+}
@@ -0,0 +1,174 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func versionHandler(version string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(version))
+	}
+}
+
+func TestRouteGroupVersions(t *testing.T) {
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	r := mux.NewRouter()
+	api := NewRouteGroup(r, "/api")
+
+	pathV1 := api.Version("v1", &VersionConfig{Deprecated: true, Sunset: sunset, Link: "/api/v2"})
+	pathV1.HandleFunc("/users", versionHandler("path-v1")).Methods(http.MethodGet)
+	api.Version("v2", nil).HandleFunc("/users", versionHandler("path-v2")).Methods(http.MethodGet)
+
+	hdr := NewRouteGroup(r, "/hdr")
+	hdr.Version("v2", &VersionConfig{Strategy: VERSION_STRATEGY_HEADER}).HandleFunc("/users", versionHandler("header-v2"))
+	hdr.Version("v1", &VersionConfig{Strategy: VERSION_STRATEGY_HEADER, Default: true}).HandleFunc("/users", versionHandler("header-v1"))
+
+	media := NewRouteGroup(r, "/media")
+	media.Version("v2", &VersionConfig{Strategy: VERSION_STRATEGY_MEDIA_TYPE, Vendor: "MyApp"}).HandleFunc("/users", versionHandler("media-v2"))
+	media.Version("v1", &VersionConfig{Strategy: VERSION_STRATEGY_MEDIA_TYPE, Vendor: "myapp", Default: true}).HandleFunc("/users", versionHandler("media-v1"))
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "path v1", path: "/api/v1/users", wantStatus: 200, wantBody: "path-v1"},
+		{name: "path v2", path: "/api/v2/users", wantStatus: 200, wantBody: "path-v2"},
+		{name: "path unknown", path: "/api/v3/users", wantStatus: 404},
+		{name: "header v2", path: "/hdr/users", header: DEFAULT_VERSION_HEADER, value: "V2", wantStatus: 200, wantBody: "header-v2"},
+		{name: "header default", path: "/hdr/users", wantStatus: 200, wantBody: "header-v1"},
+		{name: "header unknown", path: "/hdr/users", header: DEFAULT_VERSION_HEADER, value: "v9", wantStatus: 404},
+		{name: "media v2", path: "/media/users", header: "Accept", value: "text/html, application/vnd.myapp.v2+json;q=0.9", wantStatus: 200, wantBody: "media-v2"},
+		{name: "media default", path: "/media/users", header: "Accept", value: "application/json", wantStatus: 200, wantBody: "media-v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDeprecationMiddleware(t *testing.T) {
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		cfg             *VersionConfig
+		wantDeprecation string
+		wantSunset      string
+		wantLink        string
+	}{
+		{name: "deprecated", cfg: &VersionConfig{Deprecated: true}, wantDeprecation: "true"},
+		{name: "deprecated at", cfg: &VersionConfig{Deprecated: true, DeprecatedAt: deprecatedAt}, wantDeprecation: "@1767225600"},
+		{name: "sunset only", cfg: &VersionConfig{Sunset: sunset}, wantSunset: "Thu, 31 Dec 2026 00:00:00 GMT"},
+		{
+			name:            "all",
+			cfg:             &VersionConfig{Deprecated: true, Sunset: sunset, Link: "/api/v2"},
+			wantDeprecation: "true",
+			wantSunset:      "Thu, 31 Dec 2026 00:00:00 GMT",
+			wantLink:        `</api/v2>; rel="successor-version"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			deprecationMiddleware(tt.cfg)(versionHandler("ok")).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if got := w.Header().Get("Deprecation"); got != tt.wantDeprecation {
+				t.Errorf("Deprecation = %q, want %q", got, tt.wantDeprecation)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.wantSunset {
+				t.Errorf("Sunset = %q, want %q", got, tt.wantSunset)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Link = %q, want %q", got, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestRouteGroupMiddlewares(t *testing.T) {
+	mark := func(name string) mux.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Chain", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r := mux.NewRouter()
+	api := NewRouteGroup(r, "/api", mark("api"))
+	admin := api.Version("v1", nil).Group("/admin", mark("admin"))
+	admin.HandleFunc("/users", versionHandler("ok"))
+	api.HandleFunc("/public", versionHandler("ok"))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "/api/v1/admin/users", want: []string{"api", "admin"}},
+		{path: "/api/public", want: []string{"api"}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		got := w.Header().Values("X-Chain")
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s chain = %v, want %v", tt.path, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s chain = %v, want %v", tt.path, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestDumpRoutes(t *testing.T) {
+	r := mux.NewRouter()
+	api := NewRouteGroup(r, "/api")
+	api.Version("v1", nil).HandleFunc("/users/{id}", versionHandler("v1")).Methods(http.MethodGet, http.MethodDelete).Name("user")
+	api.HandleFunc("/health", versionHandler("ok"))
+
+	routes := DumpRoutes(r)
+	if len(routes) != 2 {
+		t.Fatalf("routes = %+v", routes)
+	}
+
+	want := []RouteInfo{
+		{Methods: []string{http.MethodGet, http.MethodDelete}, Path: "/api/v1/users/{id}", Name: "user", Version: "v1"},
+		{Path: "/api/health"},
+	}
+	for i, route := range routes {
+		if route.Path != want[i].Path || route.Name != want[i].Name || route.Version != want[i].Version || len(route.Methods) != len(want[i].Methods) {
+			t.Errorf("routes[%d] = %+v, want %+v", i, route, want[i])
+		}
+	}
+}
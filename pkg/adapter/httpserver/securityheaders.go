@@ -0,0 +1,160 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_SEC_HSTS_MAX_AGE       = 31536000 // 1 ano
+	DEFAULT_SEC_CSP                = "default-src 'self'; object-src 'none'; base-uri 'self'; frame-ancestors 'none'"
+	DEFAULT_SEC_FRAME_OPTIONS      = "DENY"
+	DEFAULT_SEC_REFERRER_POLICY    = "strict-origin-when-cross-origin"
+	DEFAULT_SEC_PERMISSIONS_POLICY = "camera=(), microphone=(), geolocation=(), payment=()"
+
+	// CSP_NONCE_PLACEHOLDER é trocado por um nonce aleatório em cada requisição
+	CSP_NONCE_PLACEHOLDER = "{nonce}"
+)
+
+type cspNonceKey struct{}
+
+// CSPNonce retorna o nonce gerado para a requisição atual, para ser usado em
+// <script nonce="..."> quando a CSP contém CSP_NONCE_PLACEHOLDER.
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+// loadSecurityHeadersConfig lê as variáveis de ambiente dos headers de segurança.
+//
+// Os headers são habilitados por padrão quando o AppMode é production; use
+// SRV_HTTP_SEC_HEADERS_ENABLED=false para desabilitar.
+func loadSecurityHeadersConfig(conf *config.Config) {
+	SRV_HTTP_SEC_HEADERS_ENABLED := os.Getenv("SRV_HTTP_SEC_HEADERS_ENABLED")
+	if SRV_HTTP_SEC_HEADERS_ENABLED != "" {
+		var err error
+		conf.SEC_HEADERS_ENABLED, err = strconv.ParseBool(SRV_HTTP_SEC_HEADERS_ENABLED)
+		if err != nil {
+			log.Error().Str("SRV_HTTP_SEC_HEADERS_ENABLED", "Invalid value").Str("SetDefaultValue", "true").Msg(err.Error())
+			conf.SEC_HEADERS_ENABLED = true
+		}
+	} else if conf.AppMode == config.PRODUCTION {
+		conf.SEC_HEADERS_ENABLED = true
+	}
+
+	SRV_HTTP_SEC_HSTS_MAX_AGE := os.Getenv("SRV_HTTP_SEC_HSTS_MAX_AGE")
+	if SRV_HTTP_SEC_HSTS_MAX_AGE != "" {
+		var err error
+		conf.SEC_HSTS_MAX_AGE, err = strconv.Atoi(SRV_HTTP_SEC_HSTS_MAX_AGE)
+		if err != nil {
+			log.Error().Str("SRV_HTTP_SEC_HSTS_MAX_AGE", "Invalid value").Str("SetDefaultValue", "1 year").Msg(err.Error())
+			conf.SEC_HSTS_MAX_AGE = DEFAULT_SEC_HSTS_MAX_AGE
+		}
+	} else if conf.SEC_HSTS_MAX_AGE == 0 {
+		conf.SEC_HSTS_MAX_AGE = DEFAULT_SEC_HSTS_MAX_AGE
+	}
+
+	SRV_HTTP_SEC_CSP := os.Getenv("SRV_HTTP_SEC_CSP")
+	if SRV_HTTP_SEC_CSP != "" {
+		conf.SEC_CSP = SRV_HTTP_SEC_CSP
+	} else if conf.SEC_CSP == "" {
+		conf.SEC_CSP = DEFAULT_SEC_CSP
+	}
+
+	SRV_HTTP_SEC_FRAME_OPTIONS := os.Getenv("SRV_HTTP_SEC_FRAME_OPTIONS")
+	if SRV_HTTP_SEC_FRAME_OPTIONS != "" {
+		conf.SEC_FRAME_OPTIONS = SRV_HTTP_SEC_FRAME_OPTIONS
+	} else if conf.SEC_FRAME_OPTIONS == "" {
+		conf.SEC_FRAME_OPTIONS = DEFAULT_SEC_FRAME_OPTIONS
+	}
+
+	SRV_HTTP_SEC_REFERRER_POLICY := os.Getenv("SRV_HTTP_SEC_REFERRER_POLICY")
+	if SRV_HTTP_SEC_REFERRER_POLICY != "" {
+		conf.SEC_REFERRER_POLICY = SRV_HTTP_SEC_REFERRER_POLICY
+	} else if conf.SEC_REFERRER_POLICY == "" {
+		conf.SEC_REFERRER_POLICY = DEFAULT_SEC_REFERRER_POLICY
+	}
+
+	SRV_HTTP_SEC_PERMISSIONS_POLICY := os.Getenv("SRV_HTTP_SEC_PERMISSIONS_POLICY")
+	if SRV_HTTP_SEC_PERMISSIONS_POLICY != "" {
+		conf.SEC_PERMISSIONS_POLICY = SRV_HTTP_SEC_PERMISSIONS_POLICY
+	} else if conf.SEC_PERMISSIONS_POLICY == "" {
+		conf.SEC_PERMISSIONS_POLICY = DEFAULT_SEC_PERMISSIONS_POLICY
+	}
+}
+
+// SecurityHeadersMiddleware adiciona os headers HSTS, X-Content-Type-Options,
+// X-Frame-Options, Referrer-Policy, Permissions-Policy e Content-Security-Policy.
+//
+// Valores vazios no HttpConfig usam os padrões DEFAULT_SEC_*; use "-" para não
+// enviar um header específico. Quando a CSP contém {nonce}, um nonce novo é
+// gerado por requisição e pode ser lido no handler com CSPNonce(r).
+//
+// O httpserver.New já aplica este middleware quando SEC_HEADERS_ENABLED é true.
+func SecurityHeadersMiddleware(cfg *config.HttpConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.SEC_HSTS_MAX_AGE > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.SEC_HSTS_MAX_AGE) + "; includeSubDomains"
+	}
+
+	csp := headerValue(cfg.SEC_CSP, DEFAULT_SEC_CSP)
+	useNonce := strings.Contains(csp, CSP_NONCE_PLACEHOLDER)
+
+	static := map[string]string{
+		"Strict-Transport-Security": hsts,
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           headerValue(cfg.SEC_FRAME_OPTIONS, DEFAULT_SEC_FRAME_OPTIONS),
+		"Referrer-Policy":           headerValue(cfg.SEC_REFERRER_POLICY, DEFAULT_SEC_REFERRER_POLICY),
+		"Permissions-Policy":        headerValue(cfg.SEC_PERMISSIONS_POLICY, DEFAULT_SEC_PERMISSIONS_POLICY),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			for name, value := range static {
+				if value != "" {
+					h.Set(name, value)
+				}
+			}
+
+			if csp != "" {
+				if useNonce {
+					nonce := newCSPNonce()
+					h.Set("Content-Security-Policy", strings.ReplaceAll(csp, CSP_NONCE_PLACEHOLDER, nonce))
+					r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+				} else {
+					h.Set("Content-Security-Policy", csp)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func headerValue(value, def string) string {
+	switch value {
+	case "":
+		return def
+	case "-":
+		return ""
+	default:
+		return value
+	}
+}
+
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Error().Str("FunctionName", "newCSPNonce").Msg(err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
@@ -0,0 +1,155 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.HttpConfig
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			cfg:  &config.HttpConfig{SEC_HSTS_MAX_AGE: DEFAULT_SEC_HSTS_MAX_AGE},
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           DEFAULT_SEC_FRAME_OPTIONS,
+				"Referrer-Policy":           DEFAULT_SEC_REFERRER_POLICY,
+				"Permissions-Policy":        DEFAULT_SEC_PERMISSIONS_POLICY,
+				"Content-Security-Policy":   DEFAULT_SEC_CSP,
+			},
+		},
+		{
+			name: "custom values",
+			cfg: &config.HttpConfig{
+				SEC_HSTS_MAX_AGE:  60,
+				SEC_FRAME_OPTIONS: "SAMEORIGIN",
+				SEC_CSP:           "default-src 'none'",
+			},
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=60; includeSubDomains",
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			name: "dash disables header",
+			cfg:  &config.HttpConfig{SEC_CSP: "-", SEC_FRAME_OPTIONS: "-"},
+			want: map[string]string{
+				"Strict-Transport-Security": "",
+				"X-Frame-Options":           "",
+				"Content-Security-Policy":   "",
+				"X-Content-Type-Options":    "nosniff",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SecurityHeadersMiddleware(tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			for name, want := range tt.want {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityHeadersCSPNonce(t *testing.T) {
+	cfg := &config.HttpConfig{SEC_CSP: "script-src 'nonce-" + CSP_NONCE_PLACEHOLDER + "'"}
+
+	var nonces []string
+	handler := SecurityHeadersMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, CSPNonce(r))
+	}))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		nonce := nonces[i]
+		if nonce == "" {
+			t.Fatal("nonce not set in context")
+		}
+		if got, want := w.Header().Get("Content-Security-Policy"), "script-src 'nonce-"+nonce+"'"; got != want {
+			t.Errorf("csp = %q, want %q", got, want)
+		}
+	}
+
+	if nonces[0] == nonces[1] {
+		t.Error("nonce reused between requests")
+	}
+	if strings.Contains(nonces[0], CSP_NONCE_PLACEHOLDER) {
+		t.Error("placeholder not replaced")
+	}
+}
+
+func TestLoadSecurityHeadersConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		env         map[string]string
+		wantEnabled bool
+		wantMaxAge  int
+		wantCSP     string
+	}{
+		{name: "production default", mode: config.PRODUCTION, wantEnabled: true, wantMaxAge: DEFAULT_SEC_HSTS_MAX_AGE, wantCSP: DEFAULT_SEC_CSP},
+		{name: "developer default", mode: config.DEVELOPER, wantMaxAge: DEFAULT_SEC_HSTS_MAX_AGE, wantCSP: DEFAULT_SEC_CSP},
+		{
+			name:       "production disabled by env",
+			mode:       config.PRODUCTION,
+			env:        map[string]string{"SRV_HTTP_SEC_HEADERS_ENABLED": "false"},
+			wantMaxAge: DEFAULT_SEC_HSTS_MAX_AGE,
+			wantCSP:    DEFAULT_SEC_CSP,
+		},
+		{
+			name:        "env values",
+			mode:        config.DEVELOPER,
+			env:         map[string]string{"SRV_HTTP_SEC_HEADERS_ENABLED": "true", "SRV_HTTP_SEC_HSTS_MAX_AGE": "60", "SRV_HTTP_SEC_CSP": "default-src 'none'"},
+			wantEnabled: true,
+			wantMaxAge:  60,
+			wantCSP:     "default-src 'none'",
+		},
+		{
+			name:        "invalid values fall back",
+			mode:        config.DEVELOPER,
+			env:         map[string]string{"SRV_HTTP_SEC_HEADERS_ENABLED": "x", "SRV_HTTP_SEC_HSTS_MAX_AGE": "x"},
+			wantEnabled: true,
+			wantMaxAge:  DEFAULT_SEC_HSTS_MAX_AGE,
+			wantCSP:     DEFAULT_SEC_CSP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"SRV_HTTP_SEC_HEADERS_ENABLED", "SRV_HTTP_SEC_HSTS_MAX_AGE", "SRV_HTTP_SEC_CSP"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			conf := &config.Config{AppMode: tt.mode, HttpConfig: &config.HttpConfig{}}
+			loadSecurityHeadersConfig(conf)
+
+			if conf.SEC_HEADERS_ENABLED != tt.wantEnabled {
+				t.Errorf("enabled = %v, want %v", conf.SEC_HEADERS_ENABLED, tt.wantEnabled)
+			}
+			if conf.SEC_HSTS_MAX_AGE != tt.wantMaxAge {
+				t.Errorf("max age = %d, want %d", conf.SEC_HSTS_MAX_AGE, tt.wantMaxAge)
+			}
+			if conf.SEC_CSP != tt.wantCSP {
+				t.Errorf("csp = %q, want %q", conf.SEC_CSP, tt.wantCSP)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+	"github.com/go-redis/redis/v8"
+	"github.com/phuslu/log"
+)
+
+const (
+	CONTENT_TYPE_EVENT_STREAM = "text/event-stream"
+	DEFAULT_SSE_HEARTBEAT     = 15 * time.Second
+	DEFAULT_SSE_REPLAY_SIZE   = 1000
+	SSE_REDIS_STREAM_PREFIX   = "sse:"
+)
+
+var ErrSSEClosed = errors.New("sse stream closed")
+
+// SSEEvent evento enviado para o cliente
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  []byte
+	// Retry informa ao cliente quanto tempo esperar antes de reconectar
+	Retry time.Duration
+}
+
+// SSEConfig configuração para o NewSSEWriter
+type SSEConfig struct {
+	// HeartbeatInterval intervalo do comentário de keepalive (padrão: 15s, negativo desabilita)
+	HeartbeatInterval time.Duration
+	// Retry tempo de reconexão enviado ao cliente ao abrir o stream
+	Retry time.Duration
+	// Replay buffer usado para reenviar os eventos perdidos a partir do Last-Event-ID
+	Replay SSEReplayBuffer
+	// Stream nome do stream no Replay
+	Stream string
+}
+
+// SSEReplayBuffer guarda os últimos eventos de um stream para permitir que
+// clientes reconectem sem perder mensagens.
+//
+// O produtor chama Append uma única vez por evento (o ID é gerado pelo buffer)
+// e depois envia o evento retornado para as conexões abertas.
+type SSEReplayBuffer interface {
+	Append(ctx context.Context, stream string, ev *SSEEvent) (*SSEEvent, error)
+	Since(ctx context.Context, stream, lastEventID string) ([]*SSEEvent, error)
+}
+
+// SSEWriter escreve eventos no formato Server-Sent Events
+type SSEWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	ctx     context.Context
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// NewSSEWriter prepara a resposta para Server-Sent Events.
+//
+// O WriteTimeout do servidor é removido para esta resposta, um heartbeat é
+// enviado periodicamente e, se houver Replay configurado, os eventos após o
+// Last-Event-ID enviado pelo cliente são reenviados antes de retornar.
+//
+// Exemplo de Uso:
+//
+//	func events(w http.ResponseWriter, r *http.Request) {
+//	    sse, err := httpserver.NewSSEWriter(w, r, &httpserver.SSEConfig{Replay: replay, Stream: "orders"})
+//	    if err != nil {
+//	        httpserver.WriteProblem(w, r, err)
+//	        return
+//	    }
+//	    defer sse.Close()
+//
+//	    for {
+//	        select {
+//	        case <-sse.Done():
+//	            return
+//	        case ev := <-orderEvents:
+//	            if err := sse.Send(ev); err != nil {
+//	                return
+//	            }
+//	        }
+//	    }
+//	}
+func NewSSEWriter(w http.ResponseWriter, r *http.Request, cfg *SSEConfig) (*SSEWriter, error) {
+	if cfg == nil {
+		cfg = &SSEConfig{}
+	}
+
+	heartbeat := cfg.HeartbeatInterval
+	if heartbeat == 0 {
+		heartbeat = DEFAULT_SSE_HEARTBEAT
+	}
+
+	rc := http.NewResponseController(w)
+
+	// Streams ficam abertos por tempo indeterminado, então o WriteTimeout não se aplica
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", CONTENT_TYPE_EVENT_STREAM)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sse := &SSEWriter{
+		w:    w,
+		rc:   rc,
+		ctx:  r.Context(),
+		stop: make(chan struct{}),
+	}
+
+	if cfg.Retry > 0 {
+		if err := sse.write(fmt.Sprintf("retry: %d\n\n", cfg.Retry.Milliseconds())); err != nil {
+			return nil, err
+		}
+	} else if err := sse.flush(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Replay != nil {
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("lastEventId")
+		}
+
+		if lastEventID != "" {
+			events, err := cfg.Replay.Since(r.Context(), cfg.Stream, lastEventID)
+			if err != nil {
+				log.Error().Str("FunctionName", "NewSSEWriter").Str("Stream", cfg.Stream).Msg(err.Error())
+			}
+			for _, ev := range events {
+				if err := sse.Send(ev); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if heartbeat > 0 {
+		go sse.heartbeat(heartbeat)
+	}
+
+	return sse, nil
+}
+
+// Send escreve o evento e faz o flush imediatamente
+func (s *SSEWriter) Send(ev *SSEEvent) error {
+	var buf bytes.Buffer
+
+	if ev.ID != "" {
+		buf.WriteString("id: " + sanitizeSSEField(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		buf.WriteString("event: " + sanitizeSSEField(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(string(ev.Data), "\n") {
+		buf.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	buf.WriteString("\n")
+
+	return s.write(buf.String())
+}
+
+// Done é fechado quando o cliente desconecta
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Close para o heartbeat. Deve ser chamado antes do handler retornar, pois
+// depois disso o ResponseWriter não pode mais ser usado.
+func (s *SSEWriter) Close() {
+	s.stopped.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.stop)
+	})
+}
+
+func (s *SSEWriter) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *SSEWriter) write(data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.stop:
+		return ErrSSEClosed
+	case <-s.ctx.Done():
+		return ErrSSEClosed
+	default:
+	}
+
+	if _, err := s.w.Write([]byte(data)); err != nil {
+		return err
+	}
+
+	return s.flush()
+}
+
+func (s *SSEWriter) flush() error {
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func sanitizeSSEField(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
+
+type memoryReplayBuffer struct {
+	mu      sync.Mutex
+	size    int
+	seq     uint64
+	streams map[string][]*SSEEvent
+}
+
+// NewMemoryReplayBuffer guarda os últimos size eventos de cada stream em memória.
+// Os IDs são números sequenciais por instância.
+func NewMemoryReplayBuffer(size int) SSEReplayBuffer {
+	if size <= 0 {
+		size = DEFAULT_SSE_REPLAY_SIZE
+	}
+	return &memoryReplayBuffer{size: size, streams: map[string][]*SSEEvent{}}
+}
+
+func (mb *memoryReplayBuffer) Append(ctx context.Context, stream string, ev *SSEEvent) (*SSEEvent, error) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	mb.seq++
+	stored := *ev
+	stored.ID = strconv.FormatUint(mb.seq, 10)
+
+	events := append(mb.streams[stream], &stored)
+	if len(events) > mb.size {
+		events = events[len(events)-mb.size:]
+	}
+	mb.streams[stream] = events
+
+	return &stored, nil
+}
+
+func (mb *memoryReplayBuffer) Since(ctx context.Context, stream, lastEventID string) ([]*SSEEvent, error) {
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Last-Event-ID %q", lastEventID)
+	}
+
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	var result []*SSEEvent
+	for _, ev := range mb.streams[stream] {
+		if id, _ := strconv.ParseUint(ev.ID, 10, 64); id > last {
+			result = append(result, ev)
+		}
+	}
+
+	return result, nil
+}
+
+type redisReplayBuffer struct {
+	rdb    redisdb.RedisClientInterface
+	maxLen int64
+}
+
+// NewRedisReplayBuffer guarda os eventos em um Redis Stream (chave sse:<stream>),
+// compartilhando o histórico entre todas as instâncias. Os IDs são os IDs do stream.
+func NewRedisReplayBuffer(rdb redisdb.RedisClientInterface, maxLen int64) SSEReplayBuffer {
+	if maxLen <= 0 {
+		maxLen = DEFAULT_SSE_REPLAY_SIZE
+	}
+	return &redisReplayBuffer{rdb: rdb, maxLen: maxLen}
+}
+
+func (rb *redisReplayBuffer) Append(ctx context.Context, stream string, ev *SSEEvent) (*SSEEvent, error) {
+	id, err := rb.rdb.GetClient().XAdd(ctx, &redis.XAddArgs{
+		Stream: SSE_REDIS_STREAM_PREFIX + stream,
+		MaxLen: rb.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": ev.Event, "data": ev.Data},
+	}).Result()
+	if err != nil {
+		log.Error().Str("FunctionName", "redisReplayBuffer.Append").Str("ERRO_REDIS", "Erro ao tentar salvar o evento").Msg(err.Error())
+		return nil, err
+	}
+
+	stored := *ev
+	stored.ID = id
+	return &stored, nil
+}
+
+func (rb *redisReplayBuffer) Since(ctx context.Context, stream, lastEventID string) ([]*SSEEvent, error) {
+	msgs, err := rb.rdb.GetClient().XRange(ctx, SSE_REDIS_STREAM_PREFIX+stream, lastEventID, "+").Result()
+	if err != nil {
+		log.Error().Str("FunctionName", "redisReplayBuffer.Since").Str("ERRO_REDIS", "Erro ao tentar ler os eventos").Msg(err.Error())
+		return nil, err
+	}
+
+	result := make([]*SSEEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		// XRANGE inclui o próprio lastEventID, que o cliente já recebeu
+		if msg.ID == lastEventID {
+			continue
+		}
+		event, _ := msg.Values["event"].(string)
+		data, _ := msg.Values["data"].(string)
+		result = append(result, &SSEEvent{ID: msg.ID, Event: event, Data: []byte(data)})
+	}
+
+	return result, nil
+}
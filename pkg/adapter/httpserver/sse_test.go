@@ -0,0 +1,159 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSESend(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   *SSEEvent
+		want string
+	}{
+		{name: "data only", ev: &SSEEvent{Data: []byte("hello")}, want: "data: hello\n\n"},
+		{name: "all fields", ev: &SSEEvent{ID: "1", Event: "order", Retry: 3 * time.Second, Data: []byte("x")}, want: "id: 1\nevent: order\nretry: 3000\ndata: x\n\n"},
+		{name: "multiline", ev: &SSEEvent{Data: []byte("a\r\nb\nc")}, want: "data: a\ndata: b\ndata: c\n\n"},
+		{name: "sanitized fields", ev: &SSEEvent{ID: "1\n2", Event: "a\r\nb", Data: []byte("x")}, want: "id: 12\nevent: ab\ndata: x\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/events", nil)
+
+			sse, err := NewSSEWriter(w, r, &SSEConfig{HeartbeatInterval: -1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sse.Send(tt.ev); err != nil {
+				t.Fatal(err)
+			}
+			sse.Close()
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != CONTENT_TYPE_EVENT_STREAM {
+				t.Errorf("content-type = %q", ct)
+			}
+		})
+	}
+}
+
+func TestSSERetryAndHeartbeat(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+
+	sse, err := NewSSEWriter(w, r, &SSEConfig{HeartbeatInterval: 10 * time.Millisecond, Retry: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	sse.Close()
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "retry: 2000\n\n") {
+		t.Errorf("body = %q, want retry first", body)
+	}
+	if !strings.Contains(body, ": ping\n\n") {
+		t.Errorf("body = %q, want heartbeat", body)
+	}
+
+	if err := sse.Send(&SSEEvent{Data: []byte("x")}); !errors.Is(err, ErrSSEClosed) {
+		t.Errorf("Send after Close = %v", err)
+	}
+}
+
+func TestSSEClientDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+
+	sse, err := NewSSEWriter(w, r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sse.Close()
+
+	cancel()
+	select {
+	case <-sse.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done not closed")
+	}
+	if err := sse.Send(&SSEEvent{Data: []byte("x")}); !errors.Is(err, ErrSSEClosed) {
+		t.Errorf("Send after disconnect = %v", err)
+	}
+}
+
+func TestSSEReplay(t *testing.T) {
+	rdb, _ := newTestRedis(t)
+
+	buffers := map[string]SSEReplayBuffer{
+		"memory": NewMemoryReplayBuffer(3),
+		"redis":  NewRedisReplayBuffer(rdb, 100),
+	}
+
+	for name, replay := range buffers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			var ids []string
+			for _, data := range []string{"a", "b", "c"} {
+				ev, err := replay.Append(ctx, "orders", &SSEEvent{Event: "order", Data: []byte(data)})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, ev.ID)
+			}
+
+			events, err := replay.Since(ctx, "orders", ids[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 2 || string(events[0].Data) != "b" || string(events[1].Data) != "c" || events[0].Event != "order" {
+				t.Fatalf("events = %+v", events)
+			}
+
+			// O cliente reconecta com Last-Event-ID e recebe os eventos perdidos
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/events", nil)
+			r.Header.Set("Last-Event-ID", ids[1])
+
+			sse, err := NewSSEWriter(w, r, &SSEConfig{HeartbeatInterval: -1, Replay: replay, Stream: "orders"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sse.Close()
+
+			want := "id: " + ids[2] + "\nevent: order\ndata: c\n\n"
+			if got := w.Body.String(); got != want {
+				t.Errorf("body = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMemoryReplayBufferLimits(t *testing.T) {
+	ctx := context.Background()
+	replay := NewMemoryReplayBuffer(2)
+
+	for _, data := range []string{"a", "b", "c"} {
+		replay.Append(ctx, "s", &SSEEvent{Data: []byte(data)})
+	}
+
+	events, _ := replay.Since(ctx, "s", "0")
+	if len(events) != 2 || string(events[0].Data) != "b" {
+		t.Fatalf("events = %+v, want only the last 2", events)
+	}
+
+	if _, err := replay.Since(ctx, "s", "abc"); err == nil {
+		t.Fatal("expected error for invalid id")
+	}
+}
@@ -0,0 +1,347 @@
+package httpserver
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/azure/blobstorage"
+	"github.com/google/uuid"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_UPLOAD_MAX_FILE_SIZE  = 32 << 20 // 32 MiB
+	DEFAULT_UPLOAD_MAX_FILES      = 1
+	DEFAULT_UPLOAD_BLOCK_SIZE     = 4 << 20 // 4 MiB
+	DEFAULT_UPLOAD_MAX_FIELD_SIZE = 64 << 10
+	// MAX_UPLOAD_BLOCKS limite de blocos do PutBlock (block id uint16)
+	MAX_UPLOAD_BLOCKS = 1 << 16
+)
+
+var ErroHttpMsgFileTooLarge HttpMsg = HttpMsg{
+	Msg:  "Erro File Too Large",
+	Code: http.StatusRequestEntityTooLarge,
+}
+
+var ErroHttpMsgUnsupportedMediaType HttpMsg = HttpMsg{
+	Msg:  "Erro Unsupported Media Type",
+	Code: http.StatusUnsupportedMediaType,
+}
+
+var ErroHttpMsgInvalidMultipart HttpMsg = HttpMsg{
+	Msg:  "Erro Invalid Multipart Request",
+	Code: http.StatusBadRequest,
+}
+
+// UploadOptions configuração usada pelo UploadMultipart
+type UploadOptions struct {
+	// Container container do Blob Storage (obrigatório)
+	Container string
+	// Prefix prefixo adicionado ao nome dos blobs (ex: "avatars/")
+	Prefix string
+	// MaxFileSize tamanho máximo de cada arquivo em bytes (padrão: 32 MiB)
+	MaxFileSize int64
+	// MaxFiles quantidade máxima de arquivos por requisição (padrão: 1)
+	MaxFiles int
+	// AllowedContentTypes tipos aceitos; aceita curinga como "image/*" (padrão: todos)
+	AllowedContentTypes []string
+	// SniffContentType usa o tipo detectado nos primeiros bytes do arquivo em vez
+	// do Content-Type enviado pelo cliente
+	SniffContentType bool
+	// BlockSize tamanho de cada bloco enviado com PutBlock (padrão: 4 MiB)
+	BlockSize int
+	// FileFields campos do formulário aceitos como arquivo (padrão: todos)
+	FileFields []string
+	// BlobName gera o nome do blob (padrão: Prefix + uuid + extensão do arquivo)
+	BlobName func(field, fileName string) string
+	// SkipSasUrl não gera a URL SAS dos arquivos enviados
+	SkipSasUrl bool
+}
+
+// UploadedFile arquivo gravado no Blob Storage
+type UploadedFile struct {
+	Field       string `json:"field"`
+	FileName    string `json:"file_name"`
+	BlobName    string `json:"blob_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	MD5         string `json:"md5"`
+	SHA256      string `json:"sha256"`
+	URL         string `json:"url,omitempty"`
+}
+
+// UploadResult arquivos enviados e demais campos do formulário
+type UploadResult struct {
+	Files  []*UploadedFile `json:"files"`
+	Fields url.Values      `json:"fields,omitempty"`
+}
+
+// UploadMultipart lê um multipart/form-data parte por parte e envia cada arquivo
+// direto para o Blob Storage em blocos (PutBlock/MountFile), sem guardar o
+// arquivo inteiro em memória ou disco. MD5 e SHA-256 são calculados durante o
+// envio. Em caso de falha o erro retornado é sempre um *HttpMsg pronto para ser
+// escrito na resposta; blocos já enviados e não montados são descartados pelo
+// Azure automaticamente.
+//
+// Exemplo de Uso:
+//
+//	bs := blobstorage.New(conf)
+//
+//	func upload(w http.ResponseWriter, r *http.Request) {
+//	    result, err := httpserver.UploadMultipart(w, r, bs, &httpserver.UploadOptions{
+//	        Container:           "avatars",
+//	        MaxFileSize:         5 << 20,
+//	        AllowedContentTypes: []string{"image/png", "image/jpeg"},
+//	        SniffContentType:    true,
+//	    })
+//	    if err != nil {
+//	        httpserver.WriteProblem(w, r, err)
+//	        return
+//	    }
+//	    httpserver.WriteJSON(w, http.StatusCreated, result)
+//	}
+func UploadMultipart(w http.ResponseWriter, r *http.Request, bs blobstorage.BlobInterface, opts *UploadOptions) (*UploadResult, error) {
+	if opts == nil || opts.Container == "" {
+		log.Error().Str("FunctionName", "UploadMultipart").Msg("Container não informado")
+		return nil, httpMsgWithField(ErroHttpMsgInternalServerError, "")
+	}
+
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = DEFAULT_UPLOAD_MAX_FILE_SIZE
+	}
+	maxFiles := opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DEFAULT_UPLOAD_MAX_FILES
+	}
+	blockSize := opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = DEFAULT_UPLOAD_BLOCK_SIZE
+	}
+
+	// Limite do corpo inteiro: arquivos + campos + overhead do multipart
+	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize*int64(maxFiles)+DEFAULT_MAX_BODY_SIZE)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, httpMsgWithField(ErroHttpMsgInvalidMultipart, "")
+	}
+
+	result := &UploadResult{Files: []*UploadedFile{}, Fields: url.Values{}}
+	buf := make([]byte, blockSize)
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, uploadReadError(err)
+		}
+
+		field := part.FormName()
+
+		// Campo comum do formulário
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, DEFAULT_UPLOAD_MAX_FIELD_SIZE+1))
+			part.Close()
+			if err != nil {
+				return nil, uploadReadError(err)
+			}
+			if len(value) > DEFAULT_UPLOAD_MAX_FIELD_SIZE {
+				return nil, httpMsgWithField(ErroHttpMsgRequestBodyTooLarge, field)
+			}
+			result.Fields.Add(field, string(value))
+			continue
+		}
+
+		if len(opts.FileFields) > 0 && !containsString(opts.FileFields, field) {
+			part.Close()
+			return nil, &HttpMsg{Msg: "Erro Unexpected File Field", Code: http.StatusBadRequest, Field: field}
+		}
+
+		if len(result.Files) >= maxFiles {
+			part.Close()
+			return nil, &HttpMsg{Msg: "Erro Too Many Files", Code: http.StatusBadRequest, Field: field}
+		}
+
+		file, err := uploadPart(r, bs, opts, part, buf, maxFileSize)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		result.Files = append(result.Files, file)
+	}
+
+	return result, nil
+}
+
+func uploadPart(r *http.Request, bs blobstorage.BlobInterface, opts *UploadOptions, part *multipart.Part, buf []byte, maxFileSize int64) (*UploadedFile, error) {
+	ctx := r.Context()
+	field := part.FormName()
+
+	// Primeiro bloco lido antes de criar o blob para validar o tipo do arquivo
+	n, err := io.ReadFull(part, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, uploadReadError(err)
+	}
+
+	contentType := partContentType(part, buf[:n], opts.SniffContentType)
+	if !contentTypeAllowed(contentType, opts.AllowedContentTypes) {
+		return nil, httpMsgWithField(ErroHttpMsgUnsupportedMediaType, field)
+	}
+
+	file := &UploadedFile{
+		Field:       field,
+		FileName:    filepath.Base(part.FileName()),
+		ContentType: contentType,
+	}
+
+	if opts.BlobName != nil {
+		file.BlobName = opts.BlobName(field, file.FileName)
+	} else {
+		file.BlobName = opts.Prefix + uuid.New().String() + strings.ToLower(path.Ext(file.FileName))
+	}
+
+	client, err := bs.CreateBlockBlobClient(file.BlobName, opts.Container)
+	if err != nil {
+		log.Error().Str("FunctionName", "UploadMultipart").Str("BlobName", file.BlobName).Msg(err.Error())
+		return nil, httpMsgWithField(ErroHttpMsgInternalServerError, "")
+	}
+
+	md5Hash := md5.New()
+	sha256Hash := sha256.New()
+	hashes := io.MultiWriter(md5Hash, sha256Hash)
+
+	var blockIDs []string
+	for n > 0 {
+		file.Size += int64(n)
+		if file.Size > maxFileSize {
+			return nil, httpMsgWithField(ErroHttpMsgFileTooLarge, field)
+		}
+		if len(blockIDs) >= MAX_UPLOAD_BLOCKS {
+			return nil, httpMsgWithField(ErroHttpMsgFileTooLarge, field)
+		}
+
+		block := buf[:n]
+		hashes.Write(block)
+
+		blockID, err := bs.PutBlock(ctx, client, uint16(len(blockIDs)), &block)
+		if err != nil {
+			log.Error().Str("FunctionName", "UploadMultipart").Str("BlobName", file.BlobName).Int("Block", len(blockIDs)).Msg(err.Error())
+			return nil, httpMsgWithField(ErroHttpMsgInternalServerError, "")
+		}
+		blockIDs = append(blockIDs, blockID)
+
+		n, err = io.ReadFull(part, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, uploadReadError(err)
+		}
+	}
+
+	md5Sum := md5Hash.Sum(nil)
+	file.MD5 = hex.EncodeToString(md5Sum)
+	file.SHA256 = hex.EncodeToString(sha256Hash.Sum(nil))
+
+	headers := &blob.HTTPHeaders{
+		BlobContentType:        &contentType,
+		BlobContentMD5:         md5Sum,
+		BlobContentDisposition: uploadContentDisposition(file.FileName),
+	}
+	if blockIDs == nil {
+		blockIDs = []string{}
+	}
+	if err := bs.MountFileWithHeaders(ctx, client, &blockIDs, headers); err != nil {
+		log.Error().Str("FunctionName", "UploadMultipart").Str("BlobName", file.BlobName).Msg(err.Error())
+		return nil, httpMsgWithField(ErroHttpMsgInternalServerError, "")
+	}
+
+	if !opts.SkipSasUrl {
+		file.URL, err = bs.GetSasUrl(file.BlobName, opts.Container)
+		if err != nil {
+			log.Error().Str("FunctionName", "UploadMultipart").Str("BlobName", file.BlobName).Msg(err.Error())
+			return nil, httpMsgWithField(ErroHttpMsgInternalServerError, "")
+		}
+	}
+
+	log.Info().Str("FunctionName", "UploadMultipart").Str("BlobName", file.BlobName).Int64("Size", file.Size).Str("ContentType", contentType).Msg("Upload concluído")
+
+	return file, nil
+}
+
+// partContentType tipo do arquivo sem parâmetros (ex: "image/png")
+func partContentType(part *multipart.Part, head []byte, sniff bool) string {
+	contentType := part.Header.Get("Content-Type")
+	if sniff || contentType == "" {
+		contentType = http.DetectContentType(head)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType || a == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func uploadContentDisposition(fileName string) *string {
+	if fileName == "" || fileName == "." {
+		return nil
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+	if disposition == "" {
+		return nil
+	}
+	return &disposition
+}
+
+func uploadReadError(err error) *HttpMsg {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return httpMsgWithField(ErroHttpMsgRequestBodyTooLarge, "")
+	}
+	return httpMsgWithField(ErroHttpMsgInvalidMultipart, "")
+}
+
+// httpMsgWithField copia a mensagem padrão para não alterar a variável global
+func httpMsgWithField(msg HttpMsg, field string) *HttpMsg {
+	msg.Field = field
+	return &msg
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/azure/blobstorage"
+)
+
+// fakeBlob implementa apenas os métodos usados pelo UploadMultipart
+type fakeBlob struct {
+	blobstorage.BlobInterface
+
+	mu       sync.Mutex
+	blocks   [][]byte
+	mounted  map[string][]byte
+	headers  map[string]*blob.HTTPHeaders
+	current  string
+	putErr   error
+	mountErr error
+}
+
+func newFakeBlob() *fakeBlob {
+	return &fakeBlob{mounted: map[string][]byte{}, headers: map[string]*blob.HTTPHeaders{}}
+}
+
+func (f *fakeBlob) CreateBlockBlobClient(fileName, containerName string) (*blockblob.Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current = containerName + "/" + fileName
+	f.blocks = nil
+	return nil, nil
+}
+
+func (f *fakeBlob) PutBlock(ctx context.Context, client *blockblob.Client, blockID uint16, data *[]byte) (string, error) {
+	if f.putErr != nil {
+		return "", f.putErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blocks = append(f.blocks, bytes.Clone(*data))
+	return string(rune('a' + blockID)), nil
+}
+
+func (f *fakeBlob) MountFileWithHeaders(ctx context.Context, client *blockblob.Client, blockIDs *[]string, headers *blob.HTTPHeaders) error {
+	if f.mountErr != nil {
+		return f.mountErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mounted[f.current] = bytes.Join(f.blocks, nil)
+	f.headers[f.current] = headers
+	return nil
+}
+
+func (f *fakeBlob) GetSasUrl(blobName, containerName string) (string, error) {
+	return "https://blob.local/" + containerName + "/" + blobName + "?sig=x", nil
+}
+
+type uploadTestPart struct {
+	field       string
+	fileName    string
+	contentType string
+	content     string
+}
+
+func newUploadRequest(t *testing.T, parts ...uploadTestPart) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		disposition := `form-data; name="` + p.field + `"`
+		if p.fileName != "" {
+			disposition += `; filename="` + p.fileName + `"`
+		}
+		h.Set("Content-Disposition", disposition)
+		if p.contentType != "" {
+			h.Set("Content-Type", p.contentType)
+		}
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(p.content))
+	}
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+func TestUploadMultipart(t *testing.T) {
+	bs := newFakeBlob()
+	content := pngHeader + strings.Repeat("x", 24)
+
+	r := newUploadRequest(t,
+		uploadTestPart{field: "title", content: "avatar"},
+		uploadTestPart{field: "file", fileName: "../Foto.PNG", contentType: "image/png", content: content},
+	)
+
+	result, err := UploadMultipart(httptest.NewRecorder(), r, bs, &UploadOptions{
+		Container: "avatars",
+		Prefix:    "users/",
+		BlockSize: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Fields.Get("title") != "avatar" {
+		t.Errorf("fields = %v", result.Fields)
+	}
+	if len(result.Files) != 1 {
+		t.Fatalf("files = %+v", result.Files)
+	}
+
+	file := result.Files[0]
+	sum := sha256.Sum256([]byte(content))
+	if file.FileName != "Foto.PNG" || file.ContentType != "image/png" || file.Size != int64(len(content)) || file.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("file = %+v", file)
+	}
+	if !strings.HasPrefix(file.BlobName, "users/") || !strings.HasSuffix(file.BlobName, ".png") {
+		t.Errorf("blob name = %q", file.BlobName)
+	}
+	if !strings.HasPrefix(file.URL, "https://blob.local/avatars/") {
+		t.Errorf("url = %q", file.URL)
+	}
+
+	stored := bs.mounted["avatars/"+file.BlobName]
+	if string(stored) != content {
+		t.Errorf("stored = %q, want %q", stored, content)
+	}
+	if len(bs.blocks) != 4 {
+		t.Errorf("blocks = %d, want 4 blocks of 8 bytes", len(bs.blocks))
+	}
+	headers := bs.headers["avatars/"+file.BlobName]
+	if *headers.BlobContentType != "image/png" || !strings.Contains(*headers.BlobContentDisposition, "Foto.PNG") {
+		t.Errorf("headers = %+v", headers)
+	}
+}
+
+func TestUploadMultipartErrors(t *testing.T) {
+	png := uploadTestPart{field: "file", fileName: "a.png", contentType: "image/png", content: pngHeader + "data"}
+
+	tests := []struct {
+		name       string
+		parts      []uploadTestPart
+		opts       *UploadOptions
+		blob       func(*fakeBlob)
+		notForm    bool
+		wantStatus int
+		wantField  string
+	}{
+		{name: "no container", parts: []uploadTestPart{png}, opts: &UploadOptions{}, wantStatus: http.StatusInternalServerError},
+		{name: "not multipart", notForm: true, wantStatus: http.StatusBadRequest},
+		{
+			name:       "file too large",
+			parts:      []uploadTestPart{{field: "file", fileName: "a.bin", content: strings.Repeat("x", 100)}},
+			opts:       &UploadOptions{MaxFileSize: 10, BlockSize: 4},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantField:  "file",
+		},
+		{
+			name:       "content type not allowed",
+			parts:      []uploadTestPart{{field: "file", fileName: "a.txt", contentType: "text/plain", content: "hello"}},
+			opts:       &UploadOptions{AllowedContentTypes: []string{"image/*"}},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantField:  "file",
+		},
+		{
+			name:       "sniffed type not allowed",
+			parts:      []uploadTestPart{{field: "file", fileName: "a.png", contentType: "image/png", content: "<html></html>"}},
+			opts:       &UploadOptions{AllowedContentTypes: []string{"image/png"}, SniffContentType: true},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantField:  "file",
+		},
+		{name: "too many files", parts: []uploadTestPart{png, png}, wantStatus: http.StatusBadRequest, wantField: "file"},
+		{name: "unexpected field", parts: []uploadTestPart{png}, opts: &UploadOptions{FileFields: []string{"avatar"}}, wantStatus: http.StatusBadRequest, wantField: "file"},
+		{
+			name:       "field too large",
+			parts:      []uploadTestPart{{field: "bio", content: strings.Repeat("x", DEFAULT_UPLOAD_MAX_FIELD_SIZE+1)}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantField:  "bio",
+		},
+		{name: "put block error", parts: []uploadTestPart{png}, blob: func(f *fakeBlob) { f.putErr = errors.New("down") }, wantStatus: http.StatusInternalServerError},
+		{name: "mount error", parts: []uploadTestPart{png}, blob: func(f *fakeBlob) { f.mountErr = errors.New("down") }, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := newFakeBlob()
+			if tt.blob != nil {
+				tt.blob(bs)
+			}
+
+			opts := tt.opts
+			if opts == nil {
+				opts = &UploadOptions{}
+			}
+			if opts.Container == "" && tt.name != "no container" {
+				opts.Container = "files"
+			}
+
+			var r *http.Request
+			if tt.notForm {
+				r = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(`{}`))
+				r.Header.Set("Content-Type", CONTENT_TYPE_JSON)
+			} else {
+				r = newUploadRequest(t, tt.parts...)
+			}
+
+			_, err := UploadMultipart(httptest.NewRecorder(), r, bs, opts)
+
+			var msg *HttpMsg
+			if !errors.As(err, &msg) {
+				t.Fatalf("err = %v, want *HttpMsg", err)
+			}
+			if msg.Code != tt.wantStatus || msg.Field != tt.wantField {
+				t.Errorf("code = %d, field = %q, want %d, %q", msg.Code, msg.Field, tt.wantStatus, tt.wantField)
+			}
+		})
+	}
+
+	// As mensagens globais não podem ser alteradas pelos erros com Field
+	if ErroHttpMsgFileTooLarge.Field != "" || ErroHttpMsgUnsupportedMediaType.Field != "" {
+		t.Error("global HttpMsg modified")
+	}
+}
+
+func TestContentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		allowed     []string
+		want        bool
+	}{
+		{contentType: "image/png", want: true},
+		{contentType: "image/png", allowed: []string{"image/png"}, want: true},
+		{contentType: "image/png", allowed: []string{" IMAGE/* "}, want: true},
+		{contentType: "image/png", allowed: []string{"*/*"}, want: true},
+		{contentType: "application/pdf", allowed: []string{"image/*"}, want: false},
+		{contentType: "imagex/png", allowed: []string{"image/*"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := contentTypeAllowed(tt.contentType, tt.allowed); got != tt.want {
+			t.Errorf("contentTypeAllowed(%q, %v) = %v, want %v", tt.contentType, tt.allowed, got, tt.want)
+		}
+	}
+}
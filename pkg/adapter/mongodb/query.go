@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BuildFilter traduz os filtros e o cursor do query.Query em um filtro bson.
+//
+// Exemplo de Uso:
+//
+//	cur, err := coll.Find(ctx, mongodb.BuildFilter(q), mongodb.BuildFindOptions(q))
+func BuildFilter(q *query.Query) bson.D {
+	var and bson.A
+
+	for _, filter := range q.Filters {
+		var cond interface{}
+		switch filter.Op {
+		case query.OP_IN:
+			cond = bson.D{{Key: "$in", Value: bson.A(filter.Values)}}
+		case query.OP_LIKE:
+			cond = primitive.Regex{Pattern: regexp.QuoteMeta(fmt.Sprint(filter.Value)), Options: "i"}
+		default:
+			cond = bson.D{{Key: mongoOperator(filter.Op), Value: filter.Value}}
+		}
+		and = append(and, bson.D{{Key: filter.Column, Value: cond}})
+	}
+
+	// Keyset: (a > x) OR (a = x AND b > y) ... respeitando a direção de cada campo
+	// (invertida no ?before=)
+	if q.IsCursor() && len(q.After) == len(q.Sort) {
+		var or bson.A
+		for i := range q.Sort {
+			cond := bson.D{}
+			for j := 0; j < i; j++ {
+				cond = append(cond, bson.E{Key: q.Sort[j].Column, Value: q.After[j]})
+			}
+			op := "$gt"
+			if q.SortDesc(i) {
+				op = "$lt"
+			}
+			cond = append(cond, bson.E{Key: q.Sort[i].Column, Value: bson.D{{Key: op, Value: q.After[i]}}})
+			or = append(or, cond)
+		}
+		and = append(and, bson.D{{Key: "$or", Value: or}})
+	}
+
+	if len(and) == 0 {
+		return bson.D{}
+	}
+	return bson.D{{Key: "$and", Value: and}}
+}
+
+// BuildFindOptions traduz ordenação e paginação do query.Query em FindOptions
+func BuildFindOptions(q *query.Query) *options.FindOptions {
+	opts := options.Find()
+
+	if len(q.Sort) > 0 {
+		sort := bson.D{}
+		for i, s := range q.Sort {
+			direction := 1
+			if q.SortDesc(i) {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: s.Column, Value: direction})
+		}
+		opts.SetSort(sort)
+	}
+
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+	if offset := q.Offset(); offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+
+	return opts
+}
+
+func mongoOperator(op string) string {
+	switch op {
+	case query.OP_NE:
+		return "$ne"
+	case query.OP_GT:
+		return "$gt"
+	case query.OP_GTE:
+		return "$gte"
+	case query.OP_LT:
+		return "$lt"
+	case query.OP_LTE:
+		return "$lte"
+	default:
+		return "$eq"
+	}
+}
@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBuildFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *query.Query
+		want bson.D
+	}{
+		{name: "empty", q: &query.Query{}, want: bson.D{}},
+		{
+			name: "filters",
+			q: &query.Query{Filters: []query.Filter{
+				{Column: "age", Op: query.OP_GT, Value: int64(18)},
+				{Column: "role", Op: query.OP_IN, Values: []interface{}{"admin"}},
+				{Column: "name", Op: query.OP_LIKE, Value: "a.b"},
+			}},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: int64(18)}}}},
+				bson.D{{Key: "role", Value: bson.D{{Key: "$in", Value: bson.A{"admin"}}}}},
+				bson.D{{Key: "name", Value: primitive.Regex{Pattern: `a\.b`, Options: "i"}}},
+			}}},
+		},
+		{
+			name: "keyset",
+			q: &query.Query{
+				Sort:  []query.Sort{{Column: "created_at", Desc: true}, {Column: "_id"}},
+				After: []interface{}{"2026", int64(7)},
+			},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "created_at", Value: bson.D{{Key: "$lt", Value: "2026"}}}},
+					bson.D{{Key: "created_at", Value: "2026"}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: int64(7)}}}},
+				}}},
+			}}},
+		},
+		{
+			name: "keyset before",
+			q: &query.Query{
+				Sort:   []query.Sort{{Column: "created_at", Desc: true}, {Column: "_id"}},
+				After:  []interface{}{"2026", int64(7)},
+				Before: true,
+			},
+			want: bson.D{{Key: "$and", Value: bson.A{
+				bson.D{{Key: "$or", Value: bson.A{
+					bson.D{{Key: "created_at", Value: bson.D{{Key: "$gt", Value: "2026"}}}},
+					bson.D{{Key: "created_at", Value: "2026"}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: int64(7)}}}},
+				}}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildFilter(tt.q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFindOptions(t *testing.T) {
+	opts := BuildFindOptions(&query.Query{
+		Page:  3,
+		Limit: 10,
+		Sort:  []query.Sort{{Column: "created_at", Desc: true}, {Column: "_id"}},
+	})
+
+	if *opts.Limit != 10 || *opts.Skip != 20 {
+		t.Errorf("limit = %d, skip = %d", *opts.Limit, *opts.Skip)
+	}
+	want := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}
+	if !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("sort = %#v", opts.Sort)
+	}
+
+	opts = BuildFindOptions(&query.Query{Sort: []query.Sort{{Column: "created_at", Desc: true}, {Column: "_id"}}, Before: true})
+	want = bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: -1}}
+	if !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("before sort = %#v", opts.Sort)
+	}
+
+	opts = BuildFindOptions(&query.Query{Page: 3, Limit: 10, Cursor: "c"})
+	if opts.Skip != nil {
+		t.Errorf("skip = %d, cursor pagination must not skip", *opts.Skip)
+	}
+}
@@ -0,0 +1,129 @@
+package pgsql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SQLFragments partes do SQL geradas a partir de um query.Query. As colunas vêm
+// da lista de campos permitidos do ParseQuery e os valores vão sempre em Args.
+type SQLFragments struct {
+	// Where condições sem a palavra WHERE (vazio quando não há filtros)
+	Where string
+	// OrderBy ordenação sem a palavra ORDER BY (vazio quando não há sort)
+	OrderBy string
+	// Limit cláusula LIMIT/OFFSET completa
+	Limit string
+	Args  []interface{}
+}
+
+// BuildSQL traduz o query.Query em fragmentos SQL com placeholders $n a partir
+// de argStart, permitindo combinar com argumentos próprios da consulta.
+//
+// Exemplo de Uso:
+//
+//	f := pgsql.BuildSQL(q, 1)
+//	stmt := "SELECT id, name, created_at FROM users"
+//	if f.Where != "" {
+//	    stmt += " WHERE " + f.Where
+//	}
+//	if f.OrderBy != "" {
+//	    stmt += " ORDER BY " + f.OrderBy
+//	}
+//	rows, err := db.QueryContext(ctx, stmt+" "+f.Limit, f.Args...)
+func BuildSQL(q *query.Query, argStart int) *SQLFragments {
+	f := &SQLFragments{}
+	if argStart < 1 {
+		argStart = 1
+	}
+
+	arg := func(v interface{}) string {
+		f.Args = append(f.Args, v)
+		return "$" + strconv.Itoa(argStart+len(f.Args)-1)
+	}
+
+	var conditions []string
+
+	for _, filter := range q.Filters {
+		switch filter.Op {
+		case query.OP_IN:
+			placeholders := make([]string, len(filter.Values))
+			for i, v := range filter.Values {
+				placeholders[i] = arg(v)
+			}
+			conditions = append(conditions, filter.Column+" IN ("+strings.Join(placeholders, ", ")+")")
+		case query.OP_LIKE:
+			value := "%" + likeEscaper.Replace(toString(filter.Value)) + "%"
+			conditions = append(conditions, filter.Column+" ILIKE "+arg(value))
+		default:
+			conditions = append(conditions, filter.Column+" "+sqlOperator(filter.Op)+" "+arg(filter.Value))
+		}
+	}
+
+	// Keyset: (a > x) OR (a = x AND b > y) ... respeitando a direção de cada campo
+	// (invertida no ?before=)
+	if q.IsCursor() && len(q.After) == len(q.Sort) {
+		var or []string
+		for i := range q.Sort {
+			var and []string
+			for j := 0; j < i; j++ {
+				and = append(and, q.Sort[j].Column+" = "+arg(q.After[j]))
+			}
+			op := ">"
+			if q.SortDesc(i) {
+				op = "<"
+			}
+			and = append(and, q.Sort[i].Column+" "+op+" "+arg(q.After[i]))
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+		conditions = append(conditions, "("+strings.Join(or, " OR ")+")")
+	}
+
+	f.Where = strings.Join(conditions, " AND ")
+
+	order := make([]string, len(q.Sort))
+	for i, s := range q.Sort {
+		order[i] = s.Column + " ASC"
+		if q.SortDesc(i) {
+			order[i] = s.Column + " DESC"
+		}
+	}
+	f.OrderBy = strings.Join(order, ", ")
+
+	if q.Limit > 0 {
+		f.Limit = "LIMIT " + arg(q.Limit)
+	}
+	if offset := q.Offset(); offset > 0 {
+		f.Limit = strings.TrimSpace(f.Limit + " OFFSET " + arg(offset))
+	}
+
+	return f
+}
+
+func sqlOperator(op string) string {
+	switch op {
+	case query.OP_NE:
+		return "<>"
+	case query.OP_GT:
+		return ">"
+	case query.OP_GTE:
+		return ">="
+	case query.OP_LT:
+		return "<"
+	case query.OP_LTE:
+		return "<="
+	default:
+		return "="
+	}
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package pgsql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/pkg/query"
+)
+
+func TestBuildSQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		q           *query.Query
+		argStart    int
+		wantWhere   string
+		wantOrderBy string
+		wantLimit   string
+		wantArgs    []interface{}
+	}{
+		{
+			name:      "empty",
+			q:         &query.Query{},
+			wantLimit: "",
+		},
+		{
+			name:      "offset",
+			q:         &query.Query{Page: 3, Limit: 10},
+			wantLimit: "LIMIT $1 OFFSET $2",
+			wantArgs:  []interface{}{10, 20},
+		},
+		{
+			name: "filters",
+			q: &query.Query{
+				Limit: 5,
+				Filters: []query.Filter{
+					{Column: "age", Op: query.OP_GTE, Value: int64(18)},
+					{Column: "status", Op: query.OP_NE, Value: "blocked"},
+					{Column: "role", Op: query.OP_IN, Values: []interface{}{"admin", "user"}},
+					{Column: "name", Op: query.OP_LIKE, Value: `50%_a\b`},
+				},
+			},
+			argStart:  3,
+			wantWhere: `age >= $3 AND status <> $4 AND role IN ($5, $6) AND name ILIKE $7`,
+			wantLimit: "LIMIT $8",
+			wantArgs:  []interface{}{int64(18), "blocked", "admin", "user", `%50\%\_a\\b%`, 5},
+		},
+		{
+			name: "sort",
+			q: &query.Query{Sort: []query.Sort{
+				{Column: "created_at", Desc: true},
+				{Column: "id"},
+			}},
+			wantOrderBy: "created_at DESC, id ASC",
+		},
+		{
+			name: "keyset",
+			q: &query.Query{
+				Limit:  10,
+				Cursor: "c",
+				Sort:   []query.Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+				After:  []interface{}{"2026-01-01", int64(7)},
+			},
+			wantWhere:   "((created_at < $1) OR (created_at = $2 AND id > $3))",
+			wantOrderBy: "created_at DESC, id ASC",
+			wantLimit:   "LIMIT $4",
+			wantArgs:    []interface{}{"2026-01-01", "2026-01-01", int64(7), 10},
+		},
+		{
+			name: "keyset before",
+			q: &query.Query{
+				Limit:  10,
+				Cursor: "c",
+				Before: true,
+				Sort:   []query.Sort{{Column: "created_at", Desc: true}, {Column: "id"}},
+				After:  []interface{}{"2026-01-01", int64(7)},
+			},
+			wantWhere:   "((created_at > $1) OR (created_at = $2 AND id < $3))",
+			wantOrderBy: "created_at ASC, id DESC",
+			wantLimit:   "LIMIT $4",
+			wantArgs:    []interface{}{"2026-01-01", "2026-01-01", int64(7), 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := BuildSQL(tt.q, tt.argStart)
+
+			if f.Where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", f.Where, tt.wantWhere)
+			}
+			if f.OrderBy != tt.wantOrderBy {
+				t.Errorf("order by = %q, want %q", f.OrderBy, tt.wantOrderBy)
+			}
+			if f.Limit != tt.wantLimit {
+				t.Errorf("limit = %q, want %q", f.Limit, tt.wantLimit)
+			}
+			if len(f.Args) != len(tt.wantArgs) || (len(f.Args) > 0 && !reflect.DeepEqual(f.Args, tt.wantArgs)) {
+				t.Errorf("args = %#v, want %#v", f.Args, tt.wantArgs)
+			}
+		})
+	}
+}
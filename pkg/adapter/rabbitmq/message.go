@@ -0,0 +1,37 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DecodeMessage faz o unmarshal do corpo JSON da mensagem para T e valida o
+// resultado com as tags `validate` (ver pacote validator).
+//
+// Quando o corpo é um JSON válido mas falha na validação o erro retornado é um
+// validator.ValidationErrors com todos os campos inválidos, permitindo ao
+// consumer decidir entre Nack/Reject e registrar o payload no log.
+//
+// Exemplo de Uso:
+//
+//	order, err := rabbitmq.DecodeMessage[Order](msg)
+//	if err != nil {
+//	    msg.Reject(false)
+//	    return
+//	}
+func DecodeMessage[T any](msg *amqp.Delivery) (T, error) {
+	var v T
+
+	if err := json.Unmarshal(msg.Body, &v); err != nil {
+		return v, fmt.Errorf("error decoding message body: %w", err)
+	}
+
+	if err := validator.Validate(&v); err != nil {
+		return v, err
+	}
+
+	return v, nil
+}
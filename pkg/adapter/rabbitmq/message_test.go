@@ -0,0 +1,53 @@
+package rabbitmq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/faelp22/go-commons-libs/pkg/validator"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type messageTestOrder struct {
+	ID    string `json:"id" validate:"required"`
+	Total int    `json:"total" validate:"min=1"`
+}
+
+func TestDecodeMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantValidation bool
+		wantErr        bool
+	}{
+		{name: "valid", body: `{"id":"1","total":10}`},
+		{name: "invalid json", body: `{"id":`, wantErr: true},
+		{name: "validation", body: `{"total":0}`, wantErr: true, wantValidation: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := DecodeMessage[messageTestOrder](&amqp.Delivery{Body: []byte(tt.body)})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if order.ID != "1" || order.Total != 10 {
+					t.Fatalf("order = %+v", order)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var verrs validator.ValidationErrors
+			if errors.As(err, &verrs) != tt.wantValidation {
+				t.Fatalf("error = %v, validation = %v", err, tt.wantValidation)
+			}
+			if tt.wantValidation && len(verrs) != 2 {
+				t.Fatalf("errors = %v, want id and total", verrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package redisdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/phuslu/log"
+	"golang.org/x/sync/singleflight"
+)
+
+const DEFAULT_CACHE_PREFIX = "cache:"
+
+// Primeiro byte de cada entrada gravada pelo Cache
+const (
+	cacheEntryRaw byte = iota
+	cacheEntryGzip
+	cacheEntryNegative
+)
+
+var errNegativeHit = errors.New("negative cache hit")
+
+// CacheConfig configuração do Cache
+type CacheConfig struct {
+	// Prefix prefixo das chaves no Redis (padrão: cache:)
+	Prefix string
+	// Codec formato dos valores (padrão: JSONCodec)
+	Codec Codec
+	// TTL tempo de vida das entradas (padrão: DEFAULT_RDB_TTL)
+	TTL time.Duration
+	// TTLJitter variação aleatória aplicada no TTL, entre 0 e 1 (ex: 0.1 = ±10%),
+	// para que entradas gravadas juntas não expirem ao mesmo tempo
+	TTLJitter float64
+	// CompressThreshold valores codificados maiores que este tamanho em bytes são
+	// gravados com gzip (padrão: 0, sem compressão)
+	CompressThreshold int
+	// NegativeTTL tempo que um "não encontrado" do loader fica no cache, evitando
+	// consultas repetidas por chaves que não existem (padrão: 0, desabilitado)
+	NegativeTTL time.Duration
+}
+
+// Cache cache tipado sobre o redisdb
+type Cache[T any] struct {
+	rdb   RedisClientInterfaceV2
+	cfg   *CacheConfig
+	group singleflight.Group
+}
+
+// NewCache cria um cache para valores do tipo T.
+//
+// Exemplo de Uso:
+//
+//	users := redisdb.NewCache[User](rdb, &redisdb.CacheConfig{
+//	    Prefix:            "users:",
+//	    TTL:               10 * time.Minute,
+//	    TTLJitter:         0.1,
+//	    CompressThreshold: 1024,
+//	    NegativeTTL:       30 * time.Second,
+//	})
+//
+//	user, err := users.GetOrLoad(ctx, id, func(ctx context.Context) (User, error) {
+//	    user, err := repo.FindByID(ctx, id)
+//	    if err == sql.ErrNoRows {
+//	        return user, redisdb.ErrNotFound
+//	    }
+//	    return user, err
+//	})
+func NewCache[T any](rdb RedisClientInterfaceV2, cfg *CacheConfig) *Cache[T] {
+	if rdb == nil {
+		log.Fatal().Str("FunctionName", "NewCache").Msg("O cliente redisdb é obrigatório!")
+	}
+
+	if cfg == nil {
+		cfg = &CacheConfig{}
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = DEFAULT_CACHE_PREFIX
+	}
+	if cfg.Codec == nil {
+		cfg.Codec = JSONCodec
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DEFAULT_RDB_TTL
+	}
+	if cfg.TTLJitter < 0 || cfg.TTLJitter > 1 {
+		log.Error().Str("TTLJitter", "Invalid value").Str("SetDefaultValue", "0").Msg("TTLJitter deve estar entre 0 e 1")
+		cfg.TTLJitter = 0
+	}
+
+	return &Cache[T]{rdb: rdb, cfg: cfg}
+}
+
+// Get lê um valor; retorna ErrNotFound quando a chave não existe ou está no cache negativo
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	data, err := c.rdb.Get(ctx, c.cfg.Prefix+key)
+	if err != nil {
+		return value, err
+	}
+
+	return c.decode(key, data)
+}
+
+// Set grava um valor com o TTL padrão
+func (c *Cache[T]) Set(ctx context.Context, key string, value T) error {
+	return c.SetWithTTL(ctx, key, value, c.cfg.TTL)
+}
+
+// SetWithTTL grava um valor com o TTL informado (o TTLJitter também é aplicado)
+func (c *Cache[T]) SetWithTTL(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := c.encode(value)
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Set(ctx, c.cfg.Prefix+key, data, c.jitter(ttl))
+}
+
+// Delete remove as chaves do cache, inclusive as entradas negativas
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.cfg.Prefix + key
+	}
+
+	_, err := c.rdb.Delete(ctx, prefixed...)
+	return err
+}
+
+// GetOrLoad lê o valor do cache e, quando não existe, chama o loader e grava o
+// resultado. Chamadas simultâneas para a mesma chave executam o loader uma única
+// vez (single-flight). O loader deve retornar um erro com ErrNotFound
+// (errors.Is) quando o valor não existe para que o cache negativo seja usado.
+//
+// Se o Redis estiver indisponível o loader é chamado normalmente. O loader
+// recebe um contexto sem cancelamento, já que o resultado é compartilhado com
+// as demais chamadas; quem desistir de esperar recebe o erro do próprio ctx.
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil || errors.Is(err, errNegativeHit) {
+		return value, err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		log.Error().Str("FunctionName", "GetOrLoad").Str("Key", key).Msg(err.Error())
+	}
+
+	return c.load(ctx, key, loader)
+}
+
+// load executa o loader com single-flight e grava o resultado
+func (c *Cache[T]) load(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	loadCtx := context.WithoutCancel(ctx)
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		loaded, err := loader(loadCtx)
+
+		switch {
+		case err == nil:
+			if err := c.Set(loadCtx, key, loaded); err != nil {
+				log.Error().Str("FunctionName", "GetOrLoad").Str("Key", key).Msg(err.Error())
+			}
+		case errors.Is(err, ErrNotFound) && c.cfg.NegativeTTL > 0:
+			if err := c.rdb.Set(loadCtx, c.cfg.Prefix+key, []byte{cacheEntryNegative}, c.jitter(c.cfg.NegativeTTL)); err != nil {
+				log.Error().Str("FunctionName", "GetOrLoad").Str("Key", key).Msg(err.Error())
+			}
+		}
+
+		return loaded, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case result := <-ch:
+		if result.Val != nil {
+			value = result.Val.(T)
+		}
+		return value, result.Err
+	}
+}
+
+func (c *Cache[T]) encode(value T) ([]byte, error) {
+	data, err := c.cfg.Codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.CompressThreshold > 0 && len(data) > c.cfg.CompressThreshold {
+		var buf bytes.Buffer
+		buf.WriteByte(cacheEntryGzip)
+
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	return append([]byte{cacheEntryRaw}, data...), nil
+}
+
+func (c *Cache[T]) decode(key string, data []byte) (T, error) {
+	var value T
+
+	if len(data) == 0 {
+		return value, errors.New("redisdb: empty cache entry")
+	}
+
+	payload := data[1:]
+
+	switch data[0] {
+	case cacheEntryRaw:
+	case cacheEntryGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return value, err
+		}
+		defer zr.Close()
+
+		payload, err = io.ReadAll(zr)
+		if err != nil {
+			return value, err
+		}
+	case cacheEntryNegative:
+		return value, &Error{Op: "get", Key: c.cfg.Prefix + key, Kind: ErrNotFound, Err: errNegativeHit}
+	default:
+		return value, errors.New("redisdb: unknown cache entry format")
+	}
+
+	err := c.cfg.Codec.Unmarshal(payload, &value)
+	return value, err
+}
+
+func (c *Cache[T]) jitter(ttl time.Duration) time.Duration {
+	if c.cfg.TTLJitter <= 0 {
+		return ttl
+	}
+
+	delta := time.Duration(float64(ttl) * c.cfg.TTLJitter * (rand.Float64()*2 - 1))
+	if ttl+delta <= 0 {
+		return ttl
+	}
+	return ttl + delta
+}
@@ -0,0 +1,271 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type cacheTestUser struct {
+	ID   int
+	Name string
+}
+
+func TestCacheCodecs(t *testing.T) {
+	user := cacheTestUser{ID: 1, Name: strings.Repeat("ana", 100)}
+
+	tests := []struct {
+		name      string
+		cfg       *CacheConfig
+		wantEntry byte
+	}{
+		{name: "json", cfg: &CacheConfig{}, wantEntry: cacheEntryRaw},
+		{name: "gob", cfg: &CacheConfig{Codec: GobCodec}, wantEntry: cacheEntryRaw},
+		{name: "msgpack", cfg: &CacheConfig{Codec: MsgpackCodec}, wantEntry: cacheEntryRaw},
+		{name: "gzip", cfg: &CacheConfig{CompressThreshold: 64}, wantEntry: cacheEntryGzip},
+		{name: "below threshold", cfg: &CacheConfig{CompressThreshold: 4096}, wantEntry: cacheEntryRaw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, mr := newTestClient(t)
+			cache := NewCache[cacheTestUser](rs, tt.cfg)
+			ctx := context.Background()
+
+			if err := cache.Set(ctx, "1", user); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := mr.Get(DEFAULT_CACHE_PREFIX + "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored[0] != tt.wantEntry {
+				t.Errorf("entry = %d, want %d", stored[0], tt.wantEntry)
+			}
+			if ttl := mr.TTL(DEFAULT_CACHE_PREFIX + "1"); ttl != DEFAULT_RDB_TTL {
+				t.Errorf("ttl = %v, want %v", ttl, DEFAULT_RDB_TTL)
+			}
+
+			got, err := cache.Get(ctx, "1")
+			if err != nil || got != user {
+				t.Errorf("get = %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestCacheProtobuf(t *testing.T) {
+	rs, _ := newTestClient(t)
+	cache := NewCache[*wrapperspb.StringValue](rs, &CacheConfig{Codec: ProtobufCodec})
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "1", wrapperspb.String("ana")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := cache.Get(ctx, "1")
+	if err != nil || got.GetValue() != "ana" {
+		t.Errorf("get = %v, %v", got, err)
+	}
+
+	if _, err := NewCache[cacheTestUser](rs, &CacheConfig{Codec: ProtobufCodec}).Get(ctx, "1"); err == nil {
+		t.Error("expected error decoding into a non proto.Message")
+	}
+}
+
+func TestCacheGetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   string
+		wantKind error
+	}{
+		{name: "missing", wantKind: ErrNotFound},
+		{name: "empty entry", stored: ""},
+		{name: "unknown format", stored: "\x09{}"},
+		{name: "invalid gzip", stored: "\x01not-gzip"},
+		{name: "invalid json", stored: "\x00{"},
+		{name: "negative", stored: "\x02", wantKind: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, mr := newTestClient(t)
+			cache := NewCache[cacheTestUser](rs, nil)
+			if tt.name != "missing" {
+				mr.Set(DEFAULT_CACHE_PREFIX+"1", tt.stored)
+			}
+
+			_, err := cache.Get(context.Background(), "1")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if (tt.wantKind != nil) != errors.Is(err, ErrNotFound) {
+				t.Errorf("err = %v, want kind %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestCacheGetOrLoad(t *testing.T) {
+	errLoader := errors.New("db down")
+
+	tests := []struct {
+		name        string
+		cfg         *CacheConfig
+		loaderErr   error
+		wantErr     error
+		wantCalls   int32
+		wantCached  bool
+		redisClosed bool
+	}{
+		{name: "loads and caches", wantCalls: 1, wantCached: true},
+		{name: "not found without negative cache", loaderErr: ErrNotFound, wantErr: ErrNotFound, wantCalls: 2},
+		{name: "negative cache", cfg: &CacheConfig{NegativeTTL: time.Minute}, loaderErr: ErrNotFound, wantErr: ErrNotFound, wantCalls: 1, wantCached: true},
+		{name: "loader error is not cached", loaderErr: errLoader, wantErr: errLoader, wantCalls: 2},
+		{name: "redis down calls loader", wantCalls: 2, redisClosed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, mr := newTestClient(t)
+			cache := NewCache[cacheTestUser](rs, tt.cfg)
+			if tt.redisClosed {
+				mr.Close()
+			}
+
+			var calls atomic.Int32
+			loader := func(ctx context.Context) (cacheTestUser, error) {
+				calls.Add(1)
+				return cacheTestUser{ID: 1}, tt.loaderErr
+			}
+
+			for i := 0; i < 2; i++ {
+				got, err := cache.GetOrLoad(context.Background(), "1", loader)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("call %d err = %v, want %v", i, err, tt.wantErr)
+				}
+				if tt.wantErr == nil && got.ID != 1 {
+					t.Errorf("call %d value = %+v", i, got)
+				}
+			}
+
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("loader calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+			if !tt.redisClosed && mr.Exists(DEFAULT_CACHE_PREFIX+"1") != tt.wantCached {
+				t.Errorf("cached = %v, want %v", !tt.wantCached, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestCacheGetOrLoadSingleFlight(t *testing.T) {
+	rs, _ := newTestClient(t)
+	cache := NewCache[cacheTestUser](rs, nil)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (cacheTestUser, error) {
+		calls.Add(1)
+		<-release
+		return cacheTestUser{ID: 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := cache.GetOrLoad(context.Background(), "1", loader); err != nil || got.ID != 1 {
+				t.Errorf("get = %+v, %v", got, err)
+			}
+		}()
+	}
+
+	// Espera as chamadas entrarem no single-flight antes de liberar o loader
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("loader calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestCacheGetOrLoadCanceled(t *testing.T) {
+	rs, mr := newTestClient(t)
+	cache := NewCache[cacheTestUser](rs, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	loaded := make(chan struct{})
+	_, err := cache.GetOrLoad(ctx, "1", func(loadCtx context.Context) (cacheTestUser, error) {
+		defer close(loaded)
+		time.Sleep(100 * time.Millisecond)
+		// O loader não é cancelado junto com quem desistiu de esperar
+		return cacheTestUser{ID: 1}, loadCtx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+
+	<-loaded
+	deadline := time.Now().Add(time.Second)
+	for !mr.Exists(DEFAULT_CACHE_PREFIX + "1") {
+		if time.Now().After(deadline) {
+			t.Fatal("loaded value not cached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCacheDelete(t *testing.T) {
+	rs, mr := newTestClient(t)
+	cache := NewCache[cacheTestUser](rs, &CacheConfig{Prefix: "users:", NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	cache.Set(ctx, "1", cacheTestUser{ID: 1})
+	cache.GetOrLoad(ctx, "2", func(ctx context.Context) (cacheTestUser, error) {
+		return cacheTestUser{}, ErrNotFound
+	})
+
+	if err := cache.Delete(ctx, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("keys = %v, want none", keys)
+	}
+}
+
+func TestCacheJitter(t *testing.T) {
+	rs, _ := newTestClient(t)
+
+	tests := []struct {
+		name    string
+		jitter  float64
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "disabled", jitter: 0, wantMin: time.Minute, wantMax: time.Minute},
+		{name: "10%", jitter: 0.1, wantMin: 54 * time.Second, wantMax: 66 * time.Second},
+		{name: "invalid falls back to 0", jitter: 2, wantMin: time.Minute, wantMax: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache[cacheTestUser](rs, &CacheConfig{TTLJitter: tt.jitter})
+			for i := 0; i < 100; i++ {
+				if ttl := cache.jitter(time.Minute); ttl < tt.wantMin || ttl > tt.wantMax {
+					t.Fatalf("ttl = %v, want between %v and %v", ttl, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Erros retornados pela API v2. Use errors.Is para verificar o tipo:
+//
+//	data, err := rdb.Get(ctx, "user:1")
+//	if errors.Is(err, redisdb.ErrNotFound) {
+//	    // chave não existe
+//	}
+var (
+	ErrNotFound   = errors.New("redisdb: key not found")
+	ErrConnection = errors.New("redisdb: connection error")
+	ErrTimeout    = errors.New("redisdb: timeout")
+)
+
+// Error erro de uma operação no Redis. Kind é um dos erros acima (ou nil quando
+// não se encaixa em nenhum) e Err é o erro original do go-redis.
+type Error struct {
+	Op   string
+	Key  string
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("redisdb: %s %q: %s", e.Op, e.Key, e.Err.Error())
+	}
+	return fmt.Sprintf("redisdb: %s: %s", e.Op, e.Err.Error())
+}
+
+// Unwrap permite errors.Is tanto com o Kind (ErrNotFound) quanto com o erro
+// original (redis.Nil, context.DeadlineExceeded)
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// wrapError classifica o erro do go-redis
+func wrapError(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{Op: op, Key: key, Err: err}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, redis.Nil):
+		e.Kind = ErrNotFound
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		e.Kind = ErrTimeout
+	case errors.Is(err, redis.ErrClosed), errors.Is(err, io.EOF), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET), errors.As(err, &netErr):
+		e.Kind = ErrConnection
+	}
+
+	return e
+}
+
+// legacyError devolve o erro original do go-redis para a API antiga, que
+// comparava diretamente com redis.Nil
+func legacyError(err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Err
+	}
+	return err
+}
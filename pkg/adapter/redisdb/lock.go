@@ -0,0 +1,324 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_LOCK_PREFIX         = "lock:"
+	DEFAULT_LOCK_TTL            = 30 * time.Second
+	DEFAULT_LOCK_RETRY_INTERVAL = 100 * time.Millisecond
+)
+
+var (
+	// ErrLockHeld o lock pertence a outro dono
+	ErrLockHeld = errors.New("redisdb: lock is held by another owner")
+	// ErrLockNotHeld o lock expirou ou foi liberado
+	ErrLockNotHeld = errors.New("redisdb: lock not held")
+)
+
+// SET NX + INCR do contador de fencing na mesma operação
+var lockAcquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0
+`)
+
+// Remove apenas se o token ainda for o do dono
+var lockReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+var lockExtendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// LockConfig configuração do Locker
+type LockConfig struct {
+	// Prefix prefixo das chaves no Redis (padrão: lock:)
+	Prefix string
+	// TTL duração do lease; enquanto o lock estiver com o dono ele é renovado
+	// a cada TTL/3 (padrão: 30s)
+	TTL time.Duration
+	// RetryInterval intervalo entre as tentativas do Lock (padrão: 100ms)
+	RetryInterval time.Duration
+	// DisableAutoExtend não renova o lease; o lock expira depois do TTL (ou do
+	// ttl do último Extend) e o Context do Lock é cancelado nesse momento
+	DisableAutoExtend bool
+}
+
+// Locker cria locks distribuídos no Redis
+type Locker struct {
+	rdb RedisClientInterfaceV2
+	cfg *LockConfig
+}
+
+// Lock lock obtido pelo Locker
+type Lock struct {
+	// Name nome do lock
+	Name string
+	// Token identifica o dono do lock
+	Token string
+	// Fence número crescente a cada aquisição do lock. Envie junto com as
+	// escritas para que o destino rejeite operações de um dono antigo que
+	// perdeu o lock sem perceber (ex: pausa longa do GC).
+	Fence int64
+
+	locker *Locker
+	key    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	done   chan struct{}
+
+	// expires fim do lease em UnixNano, usado quando DisableAutoExtend
+	expires  atomic.Int64
+	extended chan struct{}
+}
+
+// NewLocker cria o Locker.
+//
+// Exemplo de Uso:
+//
+//	locker := redisdb.NewLocker(rdb, nil)
+//
+//	// Job que deve rodar em apenas um pod
+//	err := locker.WithLock(ctx, "cron:relatorio", func(ctx context.Context) error {
+//	    return gerarRelatorio(ctx)
+//	})
+//	if errors.Is(err, redisdb.ErrLockHeld) {
+//	    return // outro pod já está executando
+//	}
+//
+//	// Exclusão mútua aguardando a vez
+//	lock, err := locker.Lock(ctx, "pedido:"+id)
+//	if err != nil {
+//	    return err
+//	}
+//	defer lock.Unlock(context.Background())
+//	salvar(lock.Context(), pedido, lock.Fence)
+func NewLocker(rdb RedisClientInterfaceV2, cfg *LockConfig) *Locker {
+	if rdb == nil {
+		log.Fatal().Str("FunctionName", "NewLocker").Msg("O cliente redisdb é obrigatório!")
+	}
+
+	if cfg == nil {
+		cfg = &LockConfig{}
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = DEFAULT_LOCK_PREFIX
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DEFAULT_LOCK_TTL
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DEFAULT_LOCK_RETRY_INTERVAL
+	}
+
+	return &Locker{rdb: rdb, cfg: cfg}
+}
+
+// TryLock tenta obter o lock uma única vez; retorna ErrLockHeld quando ele
+// pertence a outro dono. O ctx é usado apenas na aquisição: o lock continua
+// ativo depois que ele é cancelado, até Unlock ou até o lease ser perdido.
+func (l *Locker) TryLock(ctx context.Context, name string) (*Lock, error) {
+	token := uuid.New().String()
+	key := l.cfg.Prefix + name
+	// O lease começa a contar no Redis; medir antes garante que o Context
+	// local não sobreviva a ele
+	start := time.Now()
+	fence, err := lockAcquireScript.Run(ctx, l.rdb.GetClient(),
+		[]string{key, key + ":fence"}, token, l.cfg.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return nil, wrapError("lock", key, err)
+	}
+	if fence == 0 {
+		return nil, ErrLockHeld
+	}
+
+	lockCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	lk := &Lock{
+		Name:     name,
+		Token:    token,
+		Fence:    fence,
+		locker:   l,
+		key:      key,
+		ctx:      lockCtx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		extended: make(chan struct{}, 1),
+	}
+	lk.expires.Store(start.Add(l.cfg.TTL).UnixNano())
+
+	if l.cfg.DisableAutoExtend {
+		go lk.expireAtLeaseEnd()
+	} else {
+		go lk.keepAlive()
+	}
+
+	return lk, nil
+}
+
+// Lock aguarda até obter o lock ou o ctx ser cancelado
+func (l *Locker) Lock(ctx context.Context, name string) (*Lock, error) {
+	for {
+		lk, err := l.TryLock(ctx, name)
+		if !errors.Is(err, ErrLockHeld) {
+			return lk, err
+		}
+
+		// Jitter para que as réplicas não tentem todas ao mesmo tempo
+		wait := l.cfg.RetryInterval/2 + time.Duration(rand.Int64N(int64(l.cfg.RetryInterval)))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// WithLock executa fn apenas se conseguir o lock (TryLock), liberando ao final.
+// O ctx recebido por fn é cancelado se o lock for perdido ou se o ctx for cancelado.
+func (l *Locker) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	lk, err := l.TryLock(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.cfg.TTL)
+		defer cancel()
+		if err := lk.Unlock(releaseCtx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+			log.Error().Str("FunctionName", "WithLock").Str("Lock", name).Msg(err.Error())
+		}
+	}()
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(lk.Context(), cancel)
+	defer stop()
+
+	return fn(fnCtx)
+}
+
+// Context é cancelado quando o lock é liberado ou perdido (lease não renovado)
+func (lk *Lock) Context() context.Context {
+	return lk.ctx
+}
+
+// Unlock libera o lock; retorna ErrLockNotHeld se ele já expirou ou mudou de dono
+func (lk *Lock) Unlock(ctx context.Context) error {
+	lk.stop()
+
+	deleted, err := lockReleaseScript.Run(ctx, lk.locker.rdb.GetClient(), []string{lk.key}, lk.Token).Int64()
+	if err != nil {
+		return wrapError("unlock", lk.key, err)
+	}
+	if deleted == 0 {
+		return ErrLockNotHeld
+	}
+
+	return nil
+}
+
+// Extend renova o lease com o ttl informado
+func (lk *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	start := time.Now()
+	ok, err := lockExtendScript.Run(ctx, lk.locker.rdb.GetClient(), []string{lk.key}, lk.Token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return wrapError("extend", lk.key, err)
+	}
+	if ok == 0 {
+		return ErrLockNotHeld
+	}
+
+	if lk.locker.cfg.DisableAutoExtend {
+		lk.expires.Store(start.Add(ttl).UnixNano())
+		select {
+		case lk.extended <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+func (lk *Lock) stop() {
+	lk.once.Do(func() {
+		close(lk.done)
+		lk.cancel()
+	})
+}
+
+// expireAtLeaseEnd cancela o Context quando o lease expira sem renovação
+// automática, para que o dono não continue trabalhando depois que outra réplica
+// pode ter obtido o lock
+func (lk *Lock) expireAtLeaseEnd() {
+	timer := time.NewTimer(time.Until(time.Unix(0, lk.expires.Load())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-lk.done:
+			return
+		case <-lk.extended:
+			timer.Reset(time.Until(time.Unix(0, lk.expires.Load())))
+		case <-timer.C:
+			log.Warn().Str("FunctionName", "Lock").Str("Lock", lk.Name).Msg("Lease expirado")
+			lk.stop()
+			return
+		}
+	}
+}
+
+// keepAlive renova o lease enquanto o lock estiver ativo. Se as renovações
+// falharem, o Context do lock é cancelado antes de o lease expirar.
+func (lk *Lock) keepAlive() {
+	ttl := lk.locker.cfg.TTL
+	lastExtended := time.Now()
+
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lk.done:
+			return
+		case <-ticker.C:
+			// Com timeout de TTL/6 a última tentativa termina antes de o lease expirar
+			extendCtx, cancel := context.WithTimeout(lk.ctx, ttl/6)
+			err := lk.Extend(extendCtx, ttl)
+			cancel()
+
+			switch {
+			case err == nil:
+				lastExtended = time.Now()
+			// Sem renovar até o próximo tick o lease pode expirar com o dono ainda
+			// trabalhando; desiste antes disso
+			case errors.Is(err, ErrLockNotHeld), time.Since(lastExtended)+ttl/3 >= ttl:
+				log.Warn().Str("FunctionName", "Lock").Str("Lock", lk.Name).Msg("Lock perdido")
+				lk.stop()
+				return
+			default:
+				log.Error().Str("FunctionName", "Lock").Str("Lock", lk.Name).Msg(err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+const TEST_LOCK_TTL = 300 * time.Millisecond
+
+func newTestLocker(t *testing.T, cfg *LockConfig) (*Locker, *miniredis.Miniredis) {
+	t.Helper()
+
+	rs, mr := newTestClient(t)
+	if cfg == nil {
+		cfg = &LockConfig{TTL: TEST_LOCK_TTL, RetryInterval: 5 * time.Millisecond}
+	}
+
+	return NewLocker(rs, cfg), mr
+}
+
+func TestLockMutualExclusion(t *testing.T) {
+	locker, _ := newTestLocker(t, nil)
+	ctx := context.Background()
+
+	// 20 goroutines incrementando um contador não atômico
+	var wg sync.WaitGroup
+	var inside, maxInside atomic.Int32
+	counter := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := locker.Lock(ctx, "counter")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if n := inside.Add(1); n > maxInside.Load() {
+				maxInside.Store(n)
+			}
+			v := counter
+			time.Sleep(time.Millisecond)
+			counter = v + 1
+			inside.Add(-1)
+			lock.Unlock(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if counter != 20 || maxInside.Load() != 1 {
+		t.Errorf("counter = %d, max inside = %d", counter, maxInside.Load())
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	locker, _ := newTestLocker(t, nil)
+	ctx := context.Background()
+
+	first, err := locker.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := locker.TryLock(ctx, "job"); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("err = %v, want ErrLockHeld", err)
+	}
+
+	if err := first.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	second, err := locker.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Unlock(ctx)
+	if second.Fence <= first.Fence {
+		t.Errorf("fence %d -> %d, want increasing", first.Fence, second.Fence)
+	}
+
+	// Unlock com token antigo não remove o lock do novo dono
+	if err := first.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("stale unlock err = %v, want ErrLockNotHeld", err)
+	}
+	if _, err := locker.TryLock(ctx, "job"); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("err = %v, want ErrLockHeld after stale unlock", err)
+	}
+	if first.Context().Err() == nil {
+		t.Error("context of released lock not canceled")
+	}
+}
+
+func TestTryLockErrors(t *testing.T) {
+	locker, mr := newTestLocker(t, nil)
+	mr.Close()
+
+	if _, err := locker.TryLock(context.Background(), "job"); !errors.Is(err, ErrConnection) {
+		t.Errorf("err = %v, want ErrConnection", err)
+	}
+}
+
+func TestTryLockContextOnlyForAcquisition(t *testing.T) {
+	locker, mr := newTestLocker(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lock, err := locker.TryLock(ctx, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if lock.Context().Err() != nil {
+		t.Error("lock context canceled with the acquisition ctx")
+	}
+	if !mr.Exists("lock:job") {
+		t.Error("lock released with the acquisition ctx")
+	}
+	if err := lock.Unlock(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLockLease(t *testing.T) {
+	t.Run("renewed", func(t *testing.T) {
+		locker, mr := newTestLocker(t, nil)
+		lock, err := locker.TryLock(context.Background(), "lease")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lock.Unlock(context.Background())
+
+		// O miniredis não expira chaves sozinho; o tempo é avançado manualmente
+		for i := 0; i < 5; i++ {
+			time.Sleep(TEST_LOCK_TTL / 2)
+			mr.FastForward(TEST_LOCK_TTL / 2)
+		}
+		if !mr.Exists("lock:lease") || lock.Context().Err() != nil {
+			t.Error("lease not renewed")
+		}
+	})
+
+	t.Run("expired without auto extend", func(t *testing.T) {
+		locker, mr := newTestLocker(t, &LockConfig{TTL: TEST_LOCK_TTL, DisableAutoExtend: true})
+		ctx := context.Background()
+
+		lost, err := locker.TryLock(ctx, "lost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr.FastForward(time.Second)
+		select {
+		case <-lost.Context().Done():
+		case <-time.After(2 * TEST_LOCK_TTL):
+			t.Fatal("lock context not canceled after the lease expired")
+		}
+
+		other, err := locker.TryLock(ctx, "lost")
+		if err != nil || other.Fence <= lost.Fence {
+			t.Fatalf("other = %+v, %v", other, err)
+		}
+		defer other.Unlock(ctx)
+		if err := lost.Unlock(ctx); !errors.Is(err, ErrLockNotHeld) {
+			t.Errorf("err = %v, want ErrLockNotHeld", err)
+		}
+	})
+
+	t.Run("context canceled at lease end without auto extend", func(t *testing.T) {
+		locker, _ := newTestLocker(t, &LockConfig{TTL: TEST_LOCK_TTL, DisableAutoExtend: true})
+		ctx := context.Background()
+
+		start := time.Now()
+		lock, err := locker.TryLock(ctx, "manual")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lock.Unlock(ctx)
+
+		// Extend adia o fim do lease e, junto, o cancelamento do Context
+		time.Sleep(TEST_LOCK_TTL / 2)
+		if err := lock.Extend(ctx, TEST_LOCK_TTL); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-lock.Context().Done():
+			if elapsed := time.Since(start); elapsed < TEST_LOCK_TTL*3/2 {
+				t.Errorf("canceled after %v, lease extended until %v", elapsed, TEST_LOCK_TTL*3/2)
+			}
+		case <-time.After(3 * TEST_LOCK_TTL):
+			t.Fatal("lock context not canceled at the end of the lease")
+		}
+	})
+
+	t.Run("taken by another owner", func(t *testing.T) {
+		locker, mr := newTestLocker(t, nil)
+		lock, err := locker.TryLock(context.Background(), "stolen")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr.Set("lock:stolen", "other")
+
+		select {
+		case <-lock.Context().Done():
+		case <-time.After(TEST_LOCK_TTL):
+			t.Fatal("lock context not canceled after losing the lock")
+		}
+	})
+
+	t.Run("canceled before the lease expires", func(t *testing.T) {
+		locker, mr := newTestLocker(t, nil)
+		start := time.Now()
+		lock, err := locker.TryLock(context.Background(), "down")
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr.Close()
+
+		select {
+		case <-lock.Context().Done():
+			if elapsed := time.Since(start); elapsed >= TEST_LOCK_TTL {
+				t.Errorf("canceled after %v, lease expires at %v", elapsed, TEST_LOCK_TTL)
+			}
+		case <-time.After(2 * TEST_LOCK_TTL):
+			t.Fatal("lock context not canceled with redis down")
+		}
+	})
+}
+
+func TestLockWait(t *testing.T) {
+	locker, _ := newTestLocker(t, nil)
+	ctx := context.Background()
+
+	held, err := locker.TryLock(ctx, "wait")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Lock(tctx, "wait"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		held.Unlock(ctx)
+	}()
+	lock, err := locker.Lock(ctx, "wait")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Unlock(ctx)
+}
+
+func TestWithLock(t *testing.T) {
+	locker, mr := newTestLocker(t, nil)
+	ctx := context.Background()
+
+	var runs atomic.Int32
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- locker.WithLock(ctx, "cron", func(ctx context.Context) error {
+				runs.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	held := 0
+	for err := range errs {
+		if errors.Is(err, ErrLockHeld) {
+			held++
+		} else if err != nil {
+			t.Error(err)
+		}
+	}
+	if runs.Load() != 1 || held != 2 {
+		t.Errorf("runs = %d, held = %d", runs.Load(), held)
+	}
+	if mr.Exists("lock:cron") {
+		t.Error("lock not released")
+	}
+
+	// O ctx de fn acompanha o cancelamento do ctx de quem chamou
+	cctx, cancel := context.WithCancel(ctx)
+	errFn := errors.New("fn")
+	err := locker.WithLock(cctx, "cancel", func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return errFn
+	})
+	if !errors.Is(err, errFn) {
+		t.Errorf("err = %v", err)
+	}
+}
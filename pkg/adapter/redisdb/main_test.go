@@ -0,0 +1,41 @@
+package redisdb
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/faelp22/go-commons-libs/core/config"
+)
+
+func TestMain(m *testing.M) {
+	config.NewDefaultConf().SetAppLogLevel("error")
+	os.Exit(m.Run())
+}
+
+// newTestConf aponta as variáveis SRV_RDB_* para o miniredis
+func newTestConf(t *testing.T, mr *miniredis.Miniredis) *config.Config {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	t.Setenv("SRV_RDB_HOST", host)
+	t.Setenv("SRV_RDB_PORT", port)
+	t.Setenv("SRV_RDB_PUBSUB_CHANNEL", "events")
+
+	return &config.Config{RedisDBConfig: &config.RedisDBConfig{}}
+}
+
+// newTestClient cria um cliente conectado a um miniredis exclusivo do teste
+func newTestClient(t *testing.T) (*redis_client, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb, err := NewWithError(newTestConf(t, mr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rdb.Close() })
+
+	return rdb.(*redis_client), mr
+}
@@ -0,0 +1,359 @@
+package redisdb
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_NEAR_CACHE_MAX_ENTRIES = 10000
+	DEFAULT_NEAR_CACHE_TTL         = time.Minute
+	DEFAULT_NEAR_CACHE_CHANNEL     = "nearcache:invalidate"
+	// NEAR_CACHE_INVALIDATE valor do campo "nearcache" nas mensagens de invalidação
+	NEAR_CACHE_INVALIDATE = "invalidate"
+)
+
+// NearCacheConfig configuração do NearCache
+type NearCacheConfig struct {
+	// MaxEntries quantidade máxima de entradas em memória; as menos usadas são
+	// removidas primeiro (padrão: 10000)
+	MaxEntries int
+	// LocalTTL tempo de vida das entradas em memória. Deve ser curto, já que a
+	// invalidação por pub/sub não garante entrega (padrão: 1 minuto)
+	LocalTTL time.Duration
+	// Channel canal de pub/sub usado nas invalidações, separado do canal
+	// SRV_RDB_PUBSUB_CHANNEL da aplicação (padrão: nearcache:invalidate)
+	Channel string
+}
+
+// NearCacheStats contadores de acertos e falhas de cada camada
+type NearCacheStats struct {
+	LocalHits     uint64 `json:"local_hits"`
+	LocalMisses   uint64 `json:"local_misses"`
+	RemoteHits    uint64 `json:"remote_hits"`
+	RemoteMisses  uint64 `json:"remote_misses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+}
+
+// nearCacheMessage mensagem publicada no canal de invalidação
+type nearCacheMessage struct {
+	Kind   string   `json:"nearcache"`
+	Prefix string   `json:"prefix"`
+	Keys   []string `json:"keys"`
+	Origin string   `json:"origin"`
+}
+
+type nearCacheEntry[T any] struct {
+	key     string
+	value   T
+	expires time.Time
+}
+
+// NearCache LRU em memória na frente de um Cache no Redis. Alterações feitas
+// com Set, Delete e Invalidate são publicadas no NearCacheConfig.Channel para
+// que as outras réplicas removam a cópia local.
+//
+// Os valores em memória são compartilhados entre as chamadas; não altere
+// valores de tipos ponteiro, slice ou map retornados pelo cache.
+type NearCache[T any] struct {
+	remote     *Cache[T]
+	cfg        *NearCacheConfig
+	instanceID string
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+
+	localHits     atomic.Uint64
+	localMisses   atomic.Uint64
+	remoteHits    atomic.Uint64
+	remoteMisses  atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewNearCache cria o cache em memória na frente do Cache informado. O ctx
+// controla a inscrição no pub/sub usada para receber as invalidações.
+//
+// Exemplo de Uso:
+//
+//	users := redisdb.NewCache[User](rdb, &redisdb.CacheConfig{Prefix: "users:"})
+//	near := redisdb.NewNearCache(ctx, users, &redisdb.NearCacheConfig{
+//	    MaxEntries: 5000,
+//	    LocalTTL:   30 * time.Second,
+//	})
+//
+//	user, err := near.GetOrLoad(ctx, id, loadUser)
+//
+//	// depois de alterar o usuário, remove do Redis e de todas as réplicas
+//	near.Delete(ctx, id)
+func NewNearCache[T any](ctx context.Context, remote *Cache[T], cfg *NearCacheConfig) *NearCache[T] {
+	if remote == nil {
+		log.Fatal().Str("FunctionName", "NewNearCache").Msg("O Cache remoto é obrigatório!")
+	}
+
+	if cfg == nil {
+		cfg = &NearCacheConfig{}
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DEFAULT_NEAR_CACHE_MAX_ENTRIES
+	}
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = DEFAULT_NEAR_CACHE_TTL
+	}
+	if cfg.Channel == "" {
+		cfg.Channel = DEFAULT_NEAR_CACHE_CHANNEL
+	}
+
+	nc := &NearCache[T]{
+		remote:     remote,
+		cfg:        cfg,
+		instanceID: uuid.New().String(),
+		lru:        list.New(),
+		items:      map[string]*list.Element{},
+	}
+
+	go nc.subscribe(ctx)
+
+	return nc
+}
+
+// Get lê da memória e, se não encontrar, do Redis
+func (nc *NearCache[T]) Get(ctx context.Context, key string) (T, error) {
+	if value, ok := nc.getLocal(key); ok {
+		return value, nil
+	}
+
+	value, err := nc.remote.Get(ctx, key)
+	if err != nil {
+		nc.countRemote(err)
+		return value, err
+	}
+
+	nc.remoteHits.Add(1)
+	nc.setLocal(key, value)
+	return value, nil
+}
+
+// GetOrLoad lê da memória, depois do Redis e por fim chama o loader (ver Cache.GetOrLoad)
+func (nc *NearCache[T]) GetOrLoad(ctx context.Context, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	if value, ok := nc.getLocal(key); ok {
+		return value, nil
+	}
+
+	value, err := nc.remote.Get(ctx, key)
+	if err == nil {
+		nc.remoteHits.Add(1)
+		nc.setLocal(key, value)
+		return value, nil
+	}
+
+	nc.countRemote(err)
+	if errors.Is(err, errNegativeHit) {
+		return value, err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		log.Error().Str("FunctionName", "GetOrLoad").Str("Key", key).Msg(err.Error())
+	}
+
+	value, err = nc.remote.load(ctx, key, loader)
+	if err != nil {
+		return value, err
+	}
+
+	nc.setLocal(key, value)
+	return value, nil
+}
+
+// Set grava no Redis e na memória e avisa as outras réplicas. Como no
+// Invalidate, retorna o erro da publicação; nesse caso o valor já foi gravado,
+// mas as outras réplicas podem manter a cópia antiga até o LocalTTL.
+func (nc *NearCache[T]) Set(ctx context.Context, key string, value T) error {
+	if err := nc.remote.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	nc.setLocal(key, value)
+	return nc.publish(ctx, key)
+}
+
+// Delete remove do Redis e da memória de todas as réplicas
+func (nc *NearCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if err := nc.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+
+	return nc.Invalidate(ctx, keys...)
+}
+
+// Invalidate remove apenas as cópias em memória (local e das outras réplicas),
+// mantendo o valor no Redis
+func (nc *NearCache[T]) Invalidate(ctx context.Context, keys ...string) error {
+	nc.evictLocal(keys)
+	return nc.publish(ctx, keys...)
+}
+
+// Stats retorna os contadores de acertos e falhas das duas camadas
+func (nc *NearCache[T]) Stats() NearCacheStats {
+	nc.mu.Lock()
+	entries := nc.lru.Len()
+	nc.mu.Unlock()
+
+	return NearCacheStats{
+		LocalHits:     nc.localHits.Load(),
+		LocalMisses:   nc.localMisses.Load(),
+		RemoteHits:    nc.remoteHits.Load(),
+		RemoteMisses:  nc.remoteMisses.Load(),
+		Evictions:     nc.evictions.Load(),
+		Invalidations: nc.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+func (nc *NearCache[T]) getLocal(key string) (T, bool) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	if el, ok := nc.items[key]; ok {
+		entry := el.Value.(*nearCacheEntry[T])
+		if time.Now().Before(entry.expires) {
+			nc.lru.MoveToFront(el)
+			nc.localHits.Add(1)
+			return entry.value, true
+		}
+		nc.lru.Remove(el)
+		delete(nc.items, key)
+	}
+
+	nc.localMisses.Add(1)
+	var zero T
+	return zero, false
+}
+
+func (nc *NearCache[T]) setLocal(key string, value T) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	expires := time.Now().Add(nc.cfg.LocalTTL)
+
+	if el, ok := nc.items[key]; ok {
+		entry := el.Value.(*nearCacheEntry[T])
+		entry.value = value
+		entry.expires = expires
+		nc.lru.MoveToFront(el)
+		return
+	}
+
+	nc.items[key] = nc.lru.PushFront(&nearCacheEntry[T]{key: key, value: value, expires: expires})
+
+	for nc.lru.Len() > nc.cfg.MaxEntries {
+		oldest := nc.lru.Back()
+		nc.lru.Remove(oldest)
+		delete(nc.items, oldest.Value.(*nearCacheEntry[T]).key)
+		nc.evictions.Add(1)
+	}
+}
+
+func (nc *NearCache[T]) evictLocal(keys []string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := nc.items[key]; ok {
+			nc.lru.Remove(el)
+			delete(nc.items, key)
+			nc.invalidations.Add(1)
+		}
+	}
+}
+
+func (nc *NearCache[T]) countRemote(err error) {
+	switch {
+	case errors.Is(err, errNegativeHit):
+		nc.remoteHits.Add(1)
+	case errors.Is(err, ErrNotFound):
+		nc.remoteMisses.Add(1)
+	}
+}
+
+func (nc *NearCache[T]) publish(ctx context.Context, keys ...string) error {
+	data, err := json.Marshal(nearCacheMessage{
+		Kind:   NEAR_CACHE_INVALIDATE,
+		Prefix: nc.remote.cfg.Prefix,
+		Keys:   keys,
+		Origin: nc.instanceID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nc.remote.rdb.PublishTo(ctx, nc.cfg.Channel, data)
+}
+
+// subscribe recebe as invalidações das outras réplicas até o ctx ser cancelado.
+//
+// O go-redis refaz a inscrição sozinho quando a conexão cai e as invalidações
+// publicadas nesse intervalo se perdem. Por isso a cópia local é descartada
+// quando a inscrição é confirmada de novo depois de uma reconexão.
+func (nc *NearCache[T]) subscribe(ctx context.Context) {
+	pubsub := nc.remote.rdb.GetClient().Subscribe(ctx, nc.cfg.Channel)
+	defer pubsub.Close()
+
+	subscribed := false
+	ch := pubsub.ChannelWithSubscriptions(ctx, 100)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			switch m := msg.(type) {
+			case *redis.Subscription:
+				if m.Kind != "subscribe" {
+					continue
+				}
+				if subscribed {
+					log.Warn().Str("FunctionName", "NearCache").Str("Channel", nc.cfg.Channel).Msg("Resubscribed, local cache cleared")
+					nc.clearLocal()
+				}
+				subscribed = true
+			case *redis.Message:
+				nc.onMessage(m)
+			}
+		}
+	}
+}
+
+func (nc *NearCache[T]) clearLocal() {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	nc.lru.Init()
+	nc.items = map[string]*list.Element{}
+}
+
+func (nc *NearCache[T]) onMessage(msg *redis.Message) {
+	var m nearCacheMessage
+	// Outras mensagens do canal são ignoradas
+	if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil || m.Kind != NEAR_CACHE_INVALIDATE {
+		return
+	}
+	if m.Prefix != nc.remote.cfg.Prefix || m.Origin == nc.instanceID {
+		return
+	}
+
+	nc.evictLocal(m.Keys)
+}
@@ -0,0 +1,243 @@
+package redisdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// failingPublisher simula falha apenas na publicação
+type failingPublisher struct {
+	RedisClientInterfaceV2
+}
+
+func (failingPublisher) PublishTo(ctx context.Context, channel string, message []byte) error {
+	return wrapError("publish", channel, redis.ErrClosed)
+}
+
+func newTestNearCache(t *testing.T, rdb RedisClientInterfaceV2, cfg *NearCacheConfig) *NearCache[cacheTestUser] {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return NewNearCache(ctx, NewCache[cacheTestUser](rdb, &CacheConfig{Prefix: "users:"}), cfg)
+}
+
+func TestNearCacheLayers(t *testing.T) {
+	rs, mr := newTestClient(t)
+	a := newTestNearCache(t, rs, nil)
+	b := newTestNearCache(t, rs, nil)
+	waitSubscribers(t, mr, DEFAULT_NEAR_CACHE_CHANNEL, 2)
+	ctx := context.Background()
+
+	if _, err := b.Get(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get missing err = %v", err)
+	}
+	if err := a.Set(ctx, "1", cacheTestUser{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if got, err := b.Get(ctx, "1"); err != nil || got.ID != 1 {
+			t.Fatalf("get = %+v, %v", got, err)
+		}
+	}
+
+	want := NearCacheStats{LocalHits: 1, LocalMisses: 2, RemoteHits: 1, RemoteMisses: 1, Entries: 1}
+	if stats := b.Stats(); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestNearCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(ctx context.Context, nc *NearCache[cacheTestUser]) error
+		want   cacheTestUser
+		wantOK bool
+	}{
+		{
+			name: "set",
+			change: func(ctx context.Context, nc *NearCache[cacheTestUser]) error {
+				return nc.Set(ctx, "1", cacheTestUser{ID: 2})
+			},
+			want:   cacheTestUser{ID: 2},
+			wantOK: true,
+		},
+		{
+			name:   "delete",
+			change: func(ctx context.Context, nc *NearCache[cacheTestUser]) error { return nc.Delete(ctx, "1") },
+		},
+		{
+			name:   "invalidate keeps the remote value",
+			change: func(ctx context.Context, nc *NearCache[cacheTestUser]) error { return nc.Invalidate(ctx, "1") },
+			want:   cacheTestUser{ID: 1},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, mr := newTestClient(t)
+			cfg := &NearCacheConfig{Channel: "users:invalidate"}
+			a := newTestNearCache(t, rs, cfg)
+			b := newTestNearCache(t, rs, cfg)
+			waitSubscribers(t, mr, "users:invalidate", 2)
+			ctx := context.Background()
+
+			if err := a.Set(ctx, "1", cacheTestUser{ID: 1}); err != nil {
+				t.Fatal(err)
+			}
+			b.Get(ctx, "1")
+
+			// Mensagens no canal da aplicação não afetam o near-cache
+			rs.Publish(ctx, []byte(`{"nearcache":"invalidate","prefix":"users:","keys":["1"]}`))
+			time.Sleep(20 * time.Millisecond)
+			if b.Stats().Invalidations != 0 {
+				t.Fatal("invalidated by a message on the application channel")
+			}
+
+			if err := tt.change(ctx, a); err != nil {
+				t.Fatal(err)
+			}
+			waitInvalidations(t, b, 1)
+
+			got, err := b.Get(ctx, "1")
+			if (err == nil) != tt.wantOK || got != tt.want {
+				t.Errorf("get = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNearCachePublishError(t *testing.T) {
+	rs, _ := newTestClient(t)
+	nc := newTestNearCache(t, failingPublisher{rs}, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "set", call: func() error { return nc.Set(ctx, "1", cacheTestUser{ID: 1}) }},
+		{name: "invalidate", call: func() error { return nc.Invalidate(ctx, "1") }},
+		{name: "delete", call: func() error { return nc.Delete(ctx, "1") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrConnection) {
+				t.Errorf("err = %v, want the publish error", err)
+			}
+		})
+	}
+}
+
+func TestNearCacheLocal(t *testing.T) {
+	rs, _ := newTestClient(t)
+	nc := newTestNearCache(t, rs, &NearCacheConfig{MaxEntries: 2, LocalTTL: 50 * time.Millisecond})
+
+	for _, key := range []string{"1", "2", "3"} {
+		nc.setLocal(key, cacheTestUser{})
+	}
+
+	if _, ok := nc.getLocal("1"); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	if stats := nc.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := nc.getLocal("3"); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func TestNearCacheGetOrLoad(t *testing.T) {
+	rs, _ := newTestClient(t)
+	nc := newTestNearCache(t, rs, nil)
+	ctx := context.Background()
+
+	calls := 0
+	loader := func(ctx context.Context) (cacheTestUser, error) {
+		calls++
+		return cacheTestUser{ID: 1}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if got, err := nc.GetOrLoad(ctx, "1", loader); err != nil || got.ID != 1 {
+			t.Fatalf("get = %+v, %v", got, err)
+		}
+	}
+	if calls != 1 || nc.Stats().LocalHits != 1 {
+		t.Errorf("calls = %d, stats = %+v", calls, nc.Stats())
+	}
+}
+
+func TestNearCacheOnMessage(t *testing.T) {
+	rs, _ := newTestClient(t)
+	nc := newTestNearCache(t, rs, nil)
+
+	message := func(m nearCacheMessage) string {
+		data, _ := json.Marshal(m)
+		return string(data)
+	}
+
+	tests := []struct {
+		name      string
+		payload   string
+		wantEvict bool
+	}{
+		{name: "other replica", payload: message(nearCacheMessage{Kind: NEAR_CACHE_INVALIDATE, Prefix: "users:", Keys: []string{"1"}, Origin: "other"}), wantEvict: true},
+		{name: "own message", payload: message(nearCacheMessage{Kind: NEAR_CACHE_INVALIDATE, Prefix: "users:", Keys: []string{"1"}, Origin: nc.instanceID})},
+		{name: "other prefix", payload: message(nearCacheMessage{Kind: NEAR_CACHE_INVALIDATE, Prefix: "orders:", Keys: []string{"1"}, Origin: "other"})},
+		{name: "other kind", payload: message(nearCacheMessage{Kind: "x", Prefix: "users:", Keys: []string{"1"}, Origin: "other"})},
+		{name: "not json", payload: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc.setLocal("1", cacheTestUser{ID: 1})
+			nc.onMessage(&redis.Message{Channel: DEFAULT_NEAR_CACHE_CHANNEL, Payload: tt.payload})
+
+			if _, ok := nc.getLocal("1"); ok == tt.wantEvict {
+				t.Errorf("evicted = %v, want %v", !ok, tt.wantEvict)
+			}
+		})
+	}
+}
+
+func TestNearCacheResubscribe(t *testing.T) {
+	rs, mr := newTestClient(t)
+	nc := newTestNearCache(t, rs, &NearCacheConfig{Channel: "users:invalidate"})
+	waitSubscribers(t, mr, "users:invalidate", 1)
+
+	nc.setLocal("1", cacheTestUser{ID: 1})
+
+	// A conexão cai e o go-redis refaz a inscrição sozinho
+	mr.Close()
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribers(t, mr, "users:invalidate", 1)
+
+	waitFor(t, "local cache cleared", func() bool { return nc.Stats().Entries == 0 })
+}
+
+func waitInvalidations(t *testing.T, nc *NearCache[cacheTestUser], n uint64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for nc.Stats().Invalidations < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("invalidations = %d, want %d", nc.Stats().Invalidations, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
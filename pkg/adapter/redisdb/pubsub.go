@@ -0,0 +1,347 @@
+package redisdb
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/phuslu/log"
+)
+
+// Subscription inscrição em vários canais e padrões (PSUBSCRIBE) que pode ser
+// alterada enquanto está ativa
+type Subscription struct {
+	pubsub *redis.PubSub
+
+	mu       sync.Mutex
+	channels map[string]struct{}
+	patterns map[string]struct{}
+
+	once sync.Once
+	done chan struct{}
+}
+
+// PublishTo envia uma mensagem para o canal informado
+func (rs *redis_client) PublishTo(ctx context.Context, channel string, message []byte) error {
+	err := rs.rdb.Publish(ctx, channel, message).Err()
+	if err != nil {
+		log.Error().Str("FunctionName", "PublishTo").Str("Channel", channel).Msg(err.Error())
+		return wrapError("publish", channel, err)
+	}
+
+	return nil
+}
+
+// SubscribeTo inscreve nos canais e padrões informados (ex: "pedidos.*") e chama
+// o callback em uma nova goroutine para cada mensagem, como o Subscriber. Não
+// bloqueia: a inscrição fica ativa até o ctx ser cancelado ou Close ser chamado.
+// Em mensagens recebidas por padrão o campo Pattern do *redis.Message vem preenchido.
+//
+// Exemplo de Uso:
+//
+//	sub, err := rdb.SubscribeTo(ctx, func(msg *redis.Message) {
+//	    fmt.Println(msg.Channel, msg.Payload)
+//	}, []string{"pedidos"}, []string{"usuarios.*"})
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Close()
+//
+//	sub.Add(ctx, "pagamentos")
+//	sub.Remove(ctx, "pedidos")
+func (rs *redis_client) SubscribeTo(ctx context.Context, callback func(msg *redis.Message), channels []string, patterns []string) (*Subscription, error) {
+	sub := &Subscription{
+		pubsub:   rs.rdb.Subscribe(ctx),
+		channels: map[string]struct{}{},
+		patterns: map[string]struct{}{},
+		done:     make(chan struct{}),
+	}
+
+	if err := sub.Add(ctx, channels...); err != nil {
+		sub.pubsub.Close()
+		return nil, err
+	}
+	if err := sub.AddPattern(ctx, patterns...); err != nil {
+		sub.pubsub.Close()
+		return nil, err
+	}
+
+	// Receive confirma a primeira inscrição antes de começar a ler as mensagens
+	if len(channels) > 0 || len(patterns) > 0 {
+		if _, err := sub.pubsub.Receive(ctx); err != nil {
+			sub.pubsub.Close()
+			return nil, wrapError("subscribe", strings.Join(append(channels, patterns...), ","), err)
+		}
+	}
+
+	ch := sub.pubsub.Channel()
+	log.Info().Strs("Channels", channels).Strs("Patterns", patterns).Msg("Subscribed")
+
+	go func() {
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.done:
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				go callback(msg)
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// Add inscreve em novos canais
+func (s *Subscription) Add(ctx context.Context, channels ...string) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pubsub.Subscribe(ctx, channels...); err != nil {
+		return wrapError("subscribe", strings.Join(channels, ","), err)
+	}
+	for _, channel := range channels {
+		s.channels[channel] = struct{}{}
+	}
+
+	return nil
+}
+
+// Remove cancela a inscrição nos canais informados
+func (s *Subscription) Remove(ctx context.Context, channels ...string) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pubsub.Unsubscribe(ctx, channels...); err != nil {
+		return wrapError("unsubscribe", strings.Join(channels, ","), err)
+	}
+	for _, channel := range channels {
+		delete(s.channels, channel)
+	}
+
+	return nil
+}
+
+// AddPattern inscreve em novos padrões (PSUBSCRIBE)
+func (s *Subscription) AddPattern(ctx context.Context, patterns ...string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pubsub.PSubscribe(ctx, patterns...); err != nil {
+		return wrapError("psubscribe", strings.Join(patterns, ","), err)
+	}
+	for _, pattern := range patterns {
+		s.patterns[pattern] = struct{}{}
+	}
+
+	return nil
+}
+
+// RemovePattern cancela a inscrição nos padrões informados
+func (s *Subscription) RemovePattern(ctx context.Context, patterns ...string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pubsub.PUnsubscribe(ctx, patterns...); err != nil {
+		return wrapError("punsubscribe", strings.Join(patterns, ","), err)
+	}
+	for _, pattern := range patterns {
+		delete(s.patterns, pattern)
+	}
+
+	return nil
+}
+
+// Channels canais inscritos
+func (s *Subscription) Channels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.channels)
+}
+
+// Patterns padrões inscritos
+func (s *Subscription) Patterns() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.patterns)
+}
+
+// Done é fechado quando a inscrição termina
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close encerra a inscrição
+func (s *Subscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
+
+// PubSubRouter direciona as mensagens para o handler do canal ou do padrão
+type PubSubRouter struct {
+	mu       sync.RWMutex
+	channels map[string]func(msg *redis.Message)
+	patterns map[string]func(msg *redis.Message)
+	notFound func(msg *redis.Message)
+	sub      *Subscription
+}
+
+// NewPubSubRouter cria o router.
+//
+// Exemplo de Uso:
+//
+//	router := redisdb.NewPubSubRouter()
+//	router.Handle("pedidos", onPedido)
+//	router.HandlePattern("usuarios.*", onUsuario)
+//
+//	sub, err := router.Subscribe(ctx, rdb)
+//	if err != nil {
+//	    return err
+//	}
+//	defer sub.Close()
+//
+//	// handlers registrados depois do Subscribe também inscrevem o canal
+//	router.Handle("pagamentos", onPagamento)
+func NewPubSubRouter() *PubSubRouter {
+	return &PubSubRouter{
+		channels: map[string]func(msg *redis.Message){},
+		patterns: map[string]func(msg *redis.Message){},
+	}
+}
+
+// Handle registra o handler de um canal
+func (r *PubSubRouter) Handle(channel string, handler func(msg *redis.Message)) {
+	r.mu.Lock()
+	r.channels[channel] = handler
+	sub := r.sub
+	r.mu.Unlock()
+
+	if sub != nil {
+		if err := sub.Add(context.Background(), channel); err != nil {
+			log.Error().Str("FunctionName", "PubSubRouter").Str("Channel", channel).Msg(err.Error())
+		}
+	}
+}
+
+// HandlePattern registra o handler de um padrão (ex: "pedidos.*")
+func (r *PubSubRouter) HandlePattern(pattern string, handler func(msg *redis.Message)) {
+	r.mu.Lock()
+	r.patterns[pattern] = handler
+	sub := r.sub
+	r.mu.Unlock()
+
+	if sub != nil {
+		if err := sub.AddPattern(context.Background(), pattern); err != nil {
+			log.Error().Str("FunctionName", "PubSubRouter").Str("Pattern", pattern).Msg(err.Error())
+		}
+	}
+}
+
+// Remove remove o handler e a inscrição de um canal
+func (r *PubSubRouter) Remove(channel string) {
+	r.mu.Lock()
+	delete(r.channels, channel)
+	sub := r.sub
+	r.mu.Unlock()
+
+	if sub != nil {
+		if err := sub.Remove(context.Background(), channel); err != nil {
+			log.Error().Str("FunctionName", "PubSubRouter").Str("Channel", channel).Msg(err.Error())
+		}
+	}
+}
+
+// RemovePattern remove o handler e a inscrição de um padrão
+func (r *PubSubRouter) RemovePattern(pattern string) {
+	r.mu.Lock()
+	delete(r.patterns, pattern)
+	sub := r.sub
+	r.mu.Unlock()
+
+	if sub != nil {
+		if err := sub.RemovePattern(context.Background(), pattern); err != nil {
+			log.Error().Str("FunctionName", "PubSubRouter").Str("Pattern", pattern).Msg(err.Error())
+		}
+	}
+}
+
+// NotFound handler das mensagens sem handler registrado (padrão: ignora)
+func (r *PubSubRouter) NotFound(handler func(msg *redis.Message)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notFound = handler
+}
+
+// Dispatch chama o handler da mensagem; pode ser usado como callback do SubscribeTo
+func (r *PubSubRouter) Dispatch(msg *redis.Message) {
+	r.mu.RLock()
+	var handler func(msg *redis.Message)
+	if msg.Pattern != "" {
+		handler = r.patterns[msg.Pattern]
+	} else {
+		handler = r.channels[msg.Channel]
+	}
+	if handler == nil {
+		handler = r.notFound
+	}
+	r.mu.RUnlock()
+
+	if handler == nil {
+		log.Debug().Str("FunctionName", "PubSubRouter").Str("Channel", msg.Channel).Msg("Mensagem sem handler")
+		return
+	}
+
+	handler(msg)
+}
+
+// Subscribe inscreve nos canais e padrões registrados; handlers adicionados ou
+// removidos depois alteram a inscrição
+func (r *PubSubRouter) Subscribe(ctx context.Context, rdb RedisClientInterfaceV2) (*Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, err := rdb.SubscribeTo(ctx, r.Dispatch, sortedKeys(r.channels), sortedKeys(r.patterns))
+	if err != nil {
+		return nil, err
+	}
+	r.sub = sub
+
+	return sub, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
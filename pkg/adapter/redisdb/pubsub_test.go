@@ -0,0 +1,193 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func receive(t *testing.T, ch <-chan *redis.Message) *redis.Message {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not received")
+		return nil
+	}
+}
+
+func TestSubscribeTo(t *testing.T) {
+	rs, mr := newTestClient(t)
+	ctx := context.Background()
+
+	received := make(chan *redis.Message, 10)
+	sub, err := rs.SubscribeTo(ctx, func(msg *redis.Message) { received <- msg }, []string{"orders"}, []string{"users.*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	if err := rs.PublishTo(ctx, "orders", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, received); msg.Channel != "orders" || msg.Payload != "1" || msg.Pattern != "" {
+		t.Errorf("msg = %+v", msg)
+	}
+
+	rs.PublishTo(ctx, "users.created", []byte("2"))
+	if msg := receive(t, received); msg.Channel != "users.created" || msg.Pattern != "users.*" {
+		t.Errorf("msg = %+v", msg)
+	}
+
+	if err := sub.Add(ctx, "payments"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Remove(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.AddPattern(ctx, "stock.*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.RemovePattern(ctx, "users.*"); err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribers(t, mr, "orders", 0)
+	waitSubscribers(t, mr, "payments", 1)
+
+	if !reflect.DeepEqual(sub.Channels(), []string{"payments"}) || !reflect.DeepEqual(sub.Patterns(), []string{"stock.*"}) {
+		t.Errorf("channels = %v, patterns = %v", sub.Channels(), sub.Patterns())
+	}
+
+	rs.PublishTo(ctx, "orders", []byte("ignored"))
+	rs.PublishTo(ctx, "payments", []byte("3"))
+	if msg := receive(t, received); msg.Payload != "3" {
+		t.Errorf("msg = %+v, want the payments message", msg)
+	}
+}
+
+func TestSubscribeToStop(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(cancel context.CancelFunc, sub *Subscription)
+	}{
+		{name: "close", stop: func(cancel context.CancelFunc, sub *Subscription) { sub.Close() }},
+		{name: "ctx canceled", stop: func(cancel context.CancelFunc, sub *Subscription) { cancel() }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, mr := newTestClient(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sub, err := rs.SubscribeTo(ctx, func(msg *redis.Message) {}, []string{"orders"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.stop(cancel, sub)
+			select {
+			case <-sub.Done():
+			case <-time.After(2 * time.Second):
+				t.Fatal("subscription not stopped")
+			}
+			waitSubscribers(t, mr, "orders", 0)
+
+			if err := sub.Close(); err != nil {
+				t.Errorf("second Close err = %v", err)
+			}
+		})
+	}
+}
+
+func TestPubSubRedisDown(t *testing.T) {
+	rs, mr := newTestClient(t)
+	mr.Close()
+	ctx := context.Background()
+
+	if err := rs.PublishTo(ctx, "orders", []byte("1")); !errors.Is(err, ErrConnection) {
+		t.Errorf("publish err = %v, want ErrConnection", err)
+	}
+	if _, err := rs.SubscribeTo(ctx, func(msg *redis.Message) {}, []string{"orders"}, nil); !errors.Is(err, ErrConnection) {
+		t.Errorf("subscribe err = %v, want ErrConnection", err)
+	}
+}
+
+func TestPubSubRouterDispatch(t *testing.T) {
+	var got string
+	router := NewPubSubRouter()
+	router.Handle("orders", func(msg *redis.Message) { got = "orders" })
+	router.HandlePattern("users.*", func(msg *redis.Message) { got = "users.*" })
+
+	tests := []struct {
+		name     string
+		msg      *redis.Message
+		notFound bool
+		want     string
+	}{
+		{name: "channel", msg: &redis.Message{Channel: "orders"}, want: "orders"},
+		{name: "pattern", msg: &redis.Message{Channel: "users.created", Pattern: "users.*"}, want: "users.*"},
+		{name: "pattern does not use channel handler", msg: &redis.Message{Channel: "orders", Pattern: "ord*"}},
+		{name: "no handler", msg: &redis.Message{Channel: "payments"}},
+		{name: "not found handler", msg: &redis.Message{Channel: "payments"}, notFound: true, want: "notfound"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = ""
+			router.NotFound(nil)
+			if tt.notFound {
+				router.NotFound(func(msg *redis.Message) { got = "notfound" })
+			}
+
+			router.Dispatch(tt.msg)
+			if got != tt.want {
+				t.Errorf("handler = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPubSubRouterSubscribe(t *testing.T) {
+	rs, mr := newTestClient(t)
+	ctx := context.Background()
+
+	received := make(chan *redis.Message, 10)
+	handler := func(msg *redis.Message) { received <- msg }
+
+	router := NewPubSubRouter()
+	router.Handle("orders", handler)
+
+	sub, err := router.Subscribe(ctx, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	// Handlers registrados depois do Subscribe alteram a inscrição
+	router.Handle("payments", handler)
+	router.HandlePattern("users.*", handler)
+	router.Remove("orders")
+	waitSubscribers(t, mr, "payments", 1)
+	waitSubscribers(t, mr, "orders", 0)
+
+	if !reflect.DeepEqual(sub.Channels(), []string{"payments"}) || !reflect.DeepEqual(sub.Patterns(), []string{"users.*"}) {
+		t.Errorf("channels = %v, patterns = %v", sub.Channels(), sub.Patterns())
+	}
+
+	rs.PublishTo(ctx, "users.created", []byte("1"))
+	if msg := receive(t, received); msg.Pattern != "users.*" {
+		t.Errorf("msg = %+v", msg)
+	}
+
+	router.RemovePattern("users.*")
+	if len(sub.Patterns()) != 0 {
+		t.Errorf("patterns = %v", sub.Patterns())
+	}
+}
@@ -2,10 +2,10 @@ package redisdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/faelp22/go-commons-libs/core/config"
@@ -13,6 +13,8 @@ import (
 	"github.com/phuslu/log"
 )
 
+// RedisClientInterface API original, mantida por compatibilidade. Os métodos
+// chamam a API v2 e convertem os erros em bool.
 type RedisClientInterface interface {
 	GetClient() *redis.Client
 	ReadData(ctx context.Context, key string) (data []byte, err error)
@@ -24,19 +26,73 @@ type RedisClientInterface interface {
 	Subscriber(ctx context.Context, callback func(msg *redis.Message))
 }
 
+// RedisClientInterfaceV2 API em que todas as operações retornam erro. Os erros
+// são *Error e podem ser verificados com errors.Is(err, ErrNotFound),
+// ErrConnection ou ErrTimeout.
+type RedisClientInterfaceV2 interface {
+	GetClient() *redis.Client
+	Ping(ctx context.Context) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) (deleted int64, err error)
+	HSet(ctx context.Context, key, field string, value interface{}) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Publish(ctx context.Context, message []byte) error
+	Subscribe(ctx context.Context, callback func(msg *redis.Message)) error
+	PublishTo(ctx context.Context, channel string, message []byte) error
+	SubscribeTo(ctx context.Context, callback func(msg *redis.Message), channels []string, patterns []string) (*Subscription, error)
+	Close() error
+}
+
+const (
+	DEFAULT_RDB_TTL          = 15 * time.Minute
+	DEFAULT_RDB_PING_TIMEOUT = 12 * time.Second
+)
+
 type redis_client struct {
 	rdb               *redis.Client
-	modifyLock        sync.RWMutex
 	pubSubChannelName string
 }
 
+// New cria o cliente com a API original e encerra o serviço (log.Fatal) se não
+// conseguir conectar no Redis. Prefira NewWithError em código novo.
 func New(conf *config.Config) RedisClientInterface {
+	rc, err := NewWithError(conf)
+	if err != nil {
+		log.Fatal().Str("ERRO_REDIS_CON", "Erro ao conectar no Redis").Msg(err.Error())
+	}
+
+	return rc.(*redis_client)
+}
+
+// NewWithError cria o cliente com a API v2 retornando erro em vez de encerrar o
+// serviço quando a SRV_RDB_HOST não foi informada em produção na nuvem, a DSN é
+// inválida ou o Redis não responde ao PING.
+//
+// O go-redis já é seguro para uso concorrente e mantém o próprio pool de
+// conexões, que pode ser ajustado pelas variáveis (zero usa o padrão do go-redis):
+//
+//	SRV_RDB_POOL_SIZE       conexões no pool (padrão: 10 por CPU)
+//	SRV_RDB_MIN_IDLE_CONNS  conexões ociosas mantidas abertas (padrão: 0)
+//	SRV_RDB_DIAL_TIMEOUT    timeout para conectar em ms (padrão: 5000)
+//	SRV_RDB_READ_TIMEOUT    timeout de leitura em ms (padrão: 3000)
+//	SRV_RDB_WRITE_TIMEOUT   timeout de escrita em ms (padrão: igual ao de leitura)
+//	SRV_RDB_POOL_TIMEOUT    espera por uma conexão livre do pool em ms (padrão: leitura + 1s)
+//
+// Exemplo de Uso:
+//
+//	rdb, err := redisdb.NewWithError(conf)
+//	if err != nil {
+//	    return err
+//	}
+//	defer rdb.Close()
+func NewWithError(conf *config.Config) (RedisClientInterfaceV2, error) {
 
 	SRV_RDB_HOST := os.Getenv("SRV_RDB_HOST")
 	if SRV_RDB_HOST != "" {
 		conf.RDB_HOST = SRV_RDB_HOST
 	} else if conf.AppMode == config.PRODUCTION && conf.AppTargetDeploy == config.TARGET_DEPLOY_NUVEM {
-		log.Fatal().Msg("A variável SRV_RDB_HOST é obrigatória!")
+		return nil, errors.New("redisdb: SRV_RDB_HOST is required")
 	}
 
 	SRV_RDB_PORT := os.Getenv("SRV_RDB_PORT")
@@ -67,6 +123,13 @@ func New(conf *config.Config) RedisClientInterface {
 		conf.RDB_DB = 0
 	}
 
+	conf.RDB_POOL_SIZE = envInt("SRV_RDB_POOL_SIZE", conf.RDB_POOL_SIZE)
+	conf.RDB_MIN_IDLE_CONNS = envInt("SRV_RDB_MIN_IDLE_CONNS", conf.RDB_MIN_IDLE_CONNS)
+	conf.RDB_DIAL_TIMEOUT = envInt("SRV_RDB_DIAL_TIMEOUT", conf.RDB_DIAL_TIMEOUT)
+	conf.RDB_READ_TIMEOUT = envInt("SRV_RDB_READ_TIMEOUT", conf.RDB_READ_TIMEOUT)
+	conf.RDB_WRITE_TIMEOUT = envInt("SRV_RDB_WRITE_TIMEOUT", conf.RDB_WRITE_TIMEOUT)
+	conf.RDB_POOL_TIMEOUT = envInt("SRV_RDB_POOL_TIMEOUT", conf.RDB_POOL_TIMEOUT)
+
 	if len(conf.RDB_HOST) > 3 {
 
 		// "redis://<user>:<pass>@localhost:6379/<db>"
@@ -78,7 +141,26 @@ func New(conf *config.Config) RedisClientInterface {
 
 	opt, err := redis.ParseURL(conf.RDB_DSN)
 	if err != nil {
-		log.Fatal().Str("ERRO_REDIS_CON", "Erro ao tentar fazer o Parse da DSN").Msg(err.Error())
+		return nil, fmt.Errorf("redisdb: invalid DSN: %w", err)
+	}
+
+	if conf.RDB_POOL_SIZE > 0 {
+		opt.PoolSize = conf.RDB_POOL_SIZE
+	}
+	if conf.RDB_MIN_IDLE_CONNS > 0 {
+		opt.MinIdleConns = conf.RDB_MIN_IDLE_CONNS
+	}
+	if conf.RDB_DIAL_TIMEOUT > 0 {
+		opt.DialTimeout = time.Duration(conf.RDB_DIAL_TIMEOUT) * time.Millisecond
+	}
+	if conf.RDB_READ_TIMEOUT > 0 {
+		opt.ReadTimeout = time.Duration(conf.RDB_READ_TIMEOUT) * time.Millisecond
+	}
+	if conf.RDB_WRITE_TIMEOUT > 0 {
+		opt.WriteTimeout = time.Duration(conf.RDB_WRITE_TIMEOUT) * time.Millisecond
+	}
+	if conf.RDB_POOL_TIMEOUT > 0 {
+		opt.PoolTimeout = time.Duration(conf.RDB_POOL_TIMEOUT) * time.Millisecond
 	}
 
 	rc := &redis_client{
@@ -92,59 +174,119 @@ func New(conf *config.Config) RedisClientInterface {
 		rc.pubSubChannelName = SRV_RDB_PUBSUB_CHANNEL
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*12)
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_RDB_PING_TIMEOUT)
 	defer cancel()
 
-	status := rc.rdb.Ping(ctx)
-	if status.String() != "ping: PONG" {
-		log.Fatal().Str("ERRO_REDIS_CON_PIN", "Erro ao conectar no Redis").Str("Status", status.String()).Msg(status.Err().Error())
+	if err := rc.Ping(ctx); err != nil {
+		rc.rdb.Close()
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+func envInt(name string, current int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return current
 	}
 
-	return rc
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Error().Str(name, "Invalid value").Str("SetDefaultValue", "default").Msg(err.Error())
+		return 0
+	}
+
+	return n
 }
 
 func (rs *redis_client) GetClient() *redis.Client {
 	return rs.rdb
 }
 
-func (rs *redis_client) ReadData(ctx context.Context, key string) (data []byte, err error) {
+func (rs *redis_client) Ping(ctx context.Context) error {
+	return wrapError("ping", "", rs.rdb.Ping(ctx).Err())
+}
 
-	rs.modifyLock.Lock()
-	defer rs.modifyLock.Unlock()
+func (rs *redis_client) Close() error {
+	return wrapError("close", "", rs.rdb.Close())
+}
 
-	data, err = rs.rdb.Get(ctx, key).Bytes()
+// Get lê uma chave; retorna ErrNotFound quando ela não existe
+func (rs *redis_client) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := rs.rdb.Get(ctx, key).Bytes()
 	if err != nil {
-		log.Error().Str("FunctionName", "ReadData").Str("ERRO_REDIS", "Erro ao tentar ler uma informação").Msg(err.Error())
-		return
+		return nil, wrapError("get", key, err)
 	}
 
-	return
+	return data, nil
 }
 
-func (rs *redis_client) SaveData(ctx context.Context, key string, data []byte, timer time.Duration) (ok bool) {
+// Set grava uma chave; ttl <= 0 usa DEFAULT_RDB_TTL (para chaves sem expiração use GetClient)
+func (rs *redis_client) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = DEFAULT_RDB_TTL
+	}
 
-	rs.modifyLock.Lock()
-	defer rs.modifyLock.Unlock()
+	return wrapError("set", key, rs.rdb.Set(ctx, key, data, ttl).Err())
+}
+
+// Delete remove as chaves e retorna quantas existiam
+func (rs *redis_client) Delete(ctx context.Context, keys ...string) (int64, error) {
+	deleted, err := rs.rdb.Del(ctx, keys...).Result()
+	if err != nil {
+		key := ""
+		if len(keys) == 1 {
+			key = keys[0]
+		}
+		return 0, wrapError("del", key, err)
+	}
+
+	return deleted, nil
+}
+
+// HSet grava um campo de um hashset
+func (rs *redis_client) HSet(ctx context.Context, key, field string, value interface{}) error {
+	return wrapError("hset", key, rs.rdb.HSet(ctx, key, field, value).Err())
+}
 
-	if timer <= 0 {
-		timer = time.Duration(15 * time.Minute)
+// HGetAll lê todos os campos de um hashset; retorna ErrNotFound quando ele não existe
+func (rs *redis_client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	data, err := rs.rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, wrapError("hgetall", key, err)
+	}
+	if len(data) == 0 {
+		return nil, wrapError("hgetall", key, redis.Nil)
 	}
 
-	result := rs.rdb.Set(ctx, key, data, timer)
-	if result.Val() == "1" || result.Val() == "OK" {
-		ok = true
+	return data, nil
+}
+
+func (rs *redis_client) ReadData(ctx context.Context, key string) (data []byte, err error) {
+	data, err = rs.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Error().Str("FunctionName", "ReadData").Str("ERRO_REDIS", "Erro ao tentar ler uma informação").Msg(err.Error())
+		}
+		return nil, legacyError(err)
 	}
 
 	return
 }
 
+func (rs *redis_client) SaveData(ctx context.Context, key string, data []byte, timer time.Duration) (ok bool) {
+	if err := rs.Set(ctx, key, data, timer); err != nil {
+		log.Error().Str("FunctionName", "SaveData").Str("ERRO_REDIS", "Erro ao tentar salvar uma informação").Msg(err.Error())
+		return
+	}
+	return true
+}
+
 // SaveHSetData salva um hashset
 func (rs *redis_client) SaveHSetData(ctx context.Context, key, datakey string, value interface{}) (ok bool) {
-	rs.modifyLock.Lock()
-	defer rs.modifyLock.Unlock()
-	result := rs.rdb.HSet(ctx, key, datakey, value)
-	if result.Err() != nil {
-		log.Error().Str("FunctionName", "SaveHSetData").Str("ERRO_REDIS", "Erro ao tentar salvar uma informação").Msg(result.Err().Error())
+	if err := rs.HSet(ctx, key, datakey, value); err != nil {
+		log.Error().Str("FunctionName", "SaveHSetData").Str("ERRO_REDIS", "Erro ao tentar salvar uma informação").Msg(err.Error())
 		return
 	}
 	return true
@@ -152,13 +294,13 @@ func (rs *redis_client) SaveHSetData(ctx context.Context, key, datakey string, v
 
 // ReadHSetData lê todos os dados de um hashset
 func (rs *redis_client) ReadHSetData(ctx context.Context, key string) (data map[string]string, err error) {
-	rs.modifyLock.Lock()
-	defer rs.modifyLock.Unlock()
-
-	data, err = rs.rdb.HGetAll(ctx, key).Result()
+	data, err = rs.HGetAll(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return map[string]string{}, nil
+	}
 	if err != nil {
 		log.Error().Str("FunctionName", "ReadHSetData").Str("ERRO_REDIS", "Erro ao tentar Ler uma informação").Msg(err.Error())
-		return nil, err
+		return nil, legacyError(err)
 	}
 
 	return
@@ -166,18 +308,15 @@ func (rs *redis_client) ReadHSetData(ctx context.Context, key string) (data map[
 
 // DeleteAllHSetData deleta todos os dados de um hashset
 func (rs *redis_client) DeleteAllHSetData(ctx context.Context, key string) (ok bool) {
-	rs.modifyLock.Lock()
-	defer rs.modifyLock.Unlock()
-
-	result := rs.rdb.Del(ctx, key)
-	if result.Err() != nil {
-		log.Error().Str("FunctionName", "DeleteAllHSetData").Str("ERRO_REDIS", "Erro ao tentar Deletar uma informação").Msg(result.Err().Error())
+	if _, err := rs.Delete(ctx, key); err != nil {
+		log.Error().Str("FunctionName", "DeleteAllHSetData").Str("ERRO_REDIS", "Erro ao tentar Deletar uma informação").Msg(err.Error())
 		return
 	}
 	return true
 }
 
-// Publish envia uma mensagem para um canal específico no Redis.
+// Publish envia uma mensagem para o canal SRV_RDB_PUBSUB_CHANNEL no Redis. Para
+// outros canais use PublishTo.
 //
 // Esta função recebe um contexto (ctx), um nome de canal (channel) e uma
 // mensagem (message) como parâmetros. A mensagem é do tipo []byte, permitindo
@@ -209,13 +348,7 @@ func (rs *redis_client) DeleteAllHSetData(ctx context.Context, key string) (ok b
 //	    // Tratar erro
 //	}
 func (rs *redis_client) Publish(ctx context.Context, message []byte) error {
-	err := rs.rdb.Publish(ctx, rs.pubSubChannelName, message).Err()
-	if err != nil {
-		log.Error().Str("Error to publish message: ", err.Error())
-		return err
-	}
-
-	return nil
+	return rs.PublishTo(ctx, rs.pubSubChannelName, message)
 }
 
 // Subscriber cria uma inscrição em um canal específico no Redis e processa
@@ -255,11 +388,42 @@ func (rs *redis_client) Subscriber(ctx context.Context, callback func(msg *redis
 	pubsub := rs.rdb.Subscribe(ctx, rs.pubSubChannelName)
 	defer pubsub.Close()
 
-	ch := pubsub.Channel()
-	log.Info().Msg(fmt.Sprintf("subscribed to channel: %q", rs.pubSubChannelName))
-	for msg := range ch {
-		go callback(msg)
+	// Sem confirmar a inscrição: se o Redis estiver fora o go-redis continua
+	// tentando reconectar e refaz a inscrição sozinho
+	rs.listen(ctx, pubsub.Channel(), callback)
+}
+
+// Subscribe funciona como o Subscriber, mas confirma a inscrição antes de ler
+// as mensagens, retorna erro quando ela falha e termina quando o ctx é cancelado.
+func (rs *redis_client) Subscribe(ctx context.Context, callback func(msg *redis.Message)) error {
+	pubsub := rs.rdb.Subscribe(ctx, rs.pubSubChannelName)
+	defer pubsub.Close()
+
+	// Receive confirma a inscrição antes de começar a ler as mensagens
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return wrapError("subscribe", rs.pubSubChannelName, err)
 	}
 
-	log.Info().Msg(fmt.Sprintf("subscribed to channel: %q is closed", rs.pubSubChannelName))
+	rs.listen(ctx, pubsub.Channel(), callback)
+	return nil
+}
+
+// listen entrega cada mensagem ao callback em uma goroutine até o ctx ser
+// cancelado ou o canal ser fechado
+func (rs *redis_client) listen(ctx context.Context, ch <-chan *redis.Message, callback func(msg *redis.Message)) {
+	log.Info().Msg(fmt.Sprintf("subscribed to channel: %q", rs.pubSubChannelName))
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg(fmt.Sprintf("subscribed to channel: %q is closed", rs.pubSubChannelName))
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Info().Msg(fmt.Sprintf("subscribed to channel: %q is closed", rs.pubSubChannelName))
+				return
+			}
+			go callback(msg)
+		}
+	}
 }
@@ -0,0 +1,406 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestNewWithError(t *testing.T) {
+	closed := miniredis.NewMiniRedis()
+	if err := closed.Start(); err != nil {
+		t.Fatal(err)
+	}
+	closedHost, closedPort, _ := net.SplitHostPort(closed.Addr())
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		conf     *config.Config
+		wantKind error
+	}{
+		{
+			name: "host required in production nuvem",
+			env:  map[string]string{"SRV_RDB_HOST": ""},
+			conf: &config.Config{AppMode: config.PRODUCTION, AppTargetDeploy: config.TARGET_DEPLOY_NUVEM, RedisDBConfig: &config.RedisDBConfig{}},
+		},
+		{
+			name: "invalid dsn",
+			env:  map[string]string{"SRV_RDB_HOST": ""},
+			conf: &config.Config{RedisDBConfig: &config.RedisDBConfig{RDB_DSN: "http://localhost"}},
+		},
+		{
+			name:     "ping fails",
+			env:      map[string]string{"SRV_RDB_HOST": closedHost, "SRV_RDB_PORT": closedPort, "SRV_RDB_DIAL_TIMEOUT": "200"},
+			conf:     &config.Config{RedisDBConfig: &config.RedisDBConfig{}},
+			wantKind: ErrConnection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			rdb, err := NewWithError(tt.conf)
+			if err == nil {
+				rdb.Close()
+				t.Fatal("expected error")
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("err = %v, want %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestNewWithErrorPoolSettings(t *testing.T) {
+	mr := miniredis.RunT(t)
+	conf := newTestConf(t, mr)
+	t.Setenv("SRV_RDB_POOL_SIZE", "3")
+	t.Setenv("SRV_RDB_READ_TIMEOUT", "1500")
+	t.Setenv("SRV_RDB_MIN_IDLE_CONNS", "x")
+
+	rdb, err := NewWithError(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Close()
+
+	opt := rdb.GetClient().Options()
+	if opt.PoolSize != 3 || opt.ReadTimeout != 1500*time.Millisecond || opt.MinIdleConns != 0 {
+		t.Errorf("pool size = %d, read timeout = %v, min idle = %d", opt.PoolSize, opt.ReadTimeout, opt.MinIdleConns)
+	}
+}
+
+func TestClientV2(t *testing.T) {
+	rs, mr := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := rs.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) || !errors.Is(err, redis.Nil) {
+		t.Errorf("get missing err = %v", err)
+	}
+
+	if err := rs.Set(ctx, "key", []byte("value"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("key"); ttl != DEFAULT_RDB_TTL {
+		t.Errorf("ttl = %v, want %v", ttl, DEFAULT_RDB_TTL)
+	}
+	if data, err := rs.Get(ctx, "key"); err != nil || string(data) != "value" {
+		t.Errorf("get = %q, %v", data, err)
+	}
+
+	if err := rs.HSet(ctx, "hash", "field", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := rs.HGetAll(ctx, "hash"); err != nil || data["field"] != "1" {
+		t.Errorf("hgetall = %v, %v", data, err)
+	}
+	if _, err := rs.HGetAll(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("hgetall missing err = %v", err)
+	}
+
+	if deleted, err := rs.Delete(ctx, "key", "hash", "missing"); err != nil || deleted != 2 {
+		t.Errorf("delete = %d, %v", deleted, err)
+	}
+}
+
+func TestClientV2ConnectionError(t *testing.T) {
+	rs, mr := newTestClient(t)
+	mr.Close()
+
+	ctx := context.Background()
+	checks := map[string]error{
+		"ping": rs.Ping(ctx),
+		"set":  rs.Set(ctx, "key", nil, time.Minute),
+	}
+	_, checks["get"] = rs.Get(ctx, "key")
+	_, checks["del"] = rs.Delete(ctx, "key")
+
+	for op, err := range checks {
+		var e *Error
+		if !errors.Is(err, ErrConnection) || !errors.As(err, &e) || e.Op != op {
+			t.Errorf("%s err = %v, want ErrConnection", op, err)
+		}
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+	}{
+		{name: "nil", err: nil},
+		{name: "not found", err: redis.Nil, wantKind: ErrNotFound},
+		{name: "deadline", err: context.DeadlineExceeded, wantKind: ErrTimeout},
+		{name: "closed", err: redis.ErrClosed, wantKind: ErrConnection},
+		{name: "eof", err: io.EOF, wantKind: ErrConnection},
+		{name: "net timeout", err: &net.OpError{Op: "read", Err: timeoutError{}}, wantKind: ErrTimeout},
+		{name: "other", err: errors.New("WRONGTYPE")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapError("get", "key", tt.err)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+
+			var e *Error
+			if !errors.As(err, &e) || e.Kind != tt.wantKind || !errors.Is(err, tt.err) {
+				t.Errorf("err = %#v, want kind %v", err, tt.wantKind)
+			}
+			if legacyError(err) != tt.err {
+				t.Errorf("legacyError = %v, want %v", legacyError(err), tt.err)
+			}
+		})
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClientLegacy(t *testing.T) {
+	rs, mr := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := rs.ReadData(ctx, "missing"); err != redis.Nil {
+		t.Errorf("read missing err = %v, want redis.Nil", err)
+	}
+	if !rs.SaveData(ctx, "key", []byte("value"), time.Minute) {
+		t.Fatal("SaveData failed")
+	}
+	if data, err := rs.ReadData(ctx, "key"); err != nil || string(data) != "value" {
+		t.Errorf("read = %q, %v", data, err)
+	}
+
+	if data, err := rs.ReadHSetData(ctx, "missing"); err != nil || data == nil || len(data) != 0 {
+		t.Errorf("read hset missing = %v, %v, want empty map", data, err)
+	}
+	if !rs.SaveHSetData(ctx, "hash", "field", 1) || !rs.DeleteAllHSetData(ctx, "hash") {
+		t.Error("hset operations failed")
+	}
+
+	mr.Close()
+	if rs.SaveData(ctx, "key", nil, time.Minute) {
+		t.Error("SaveData ok with redis down")
+	}
+	if _, err := rs.ReadData(ctx, "key"); err == nil || errors.Is(err, ErrConnection) {
+		t.Errorf("read err = %v, want the go-redis error", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	rs, mr := newTestClient(t)
+
+	tests := []struct {
+		name      string
+		subscribe func(ctx context.Context, callback func(msg *redis.Message)) error
+	}{
+		{name: "Subscribe", subscribe: rs.Subscribe},
+		{name: "Subscriber", subscribe: func(ctx context.Context, callback func(msg *redis.Message)) error {
+			rs.Subscriber(ctx, callback)
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			received := make(chan string, 1)
+			done := make(chan error, 1)
+			go func() {
+				done <- tt.subscribe(ctx, func(msg *redis.Message) { received <- msg.Payload })
+			}()
+
+			waitSubscribers(t, mr, "events", 1)
+			if err := rs.Publish(context.Background(), []byte("hello")); err != nil {
+				t.Fatal(err)
+			}
+
+			select {
+			case payload := <-received:
+				if payload != "hello" {
+					t.Errorf("payload = %q", payload)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("message not received")
+			}
+
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("err = %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("subscription did not stop on cancel")
+			}
+			waitSubscribers(t, mr, "events", 0)
+		})
+	}
+}
+
+func TestSubscribeRedisDown(t *testing.T) {
+	rs, mr := newTestClient(t)
+	mr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// A API v2 confirma a inscrição e retorna o erro
+	if err := rs.Subscribe(ctx, func(msg *redis.Message) {}); !errors.Is(err, ErrConnection) {
+		t.Errorf("err = %v, want ErrConnection", err)
+	}
+
+	// O Subscriber continua tentando até o ctx ser cancelado
+	subCtx, subCancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer subCancel()
+
+	start := time.Now()
+	rs.Subscriber(subCtx, func(msg *redis.Message) {})
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Subscriber returned after %v, want it to keep retrying", elapsed)
+	}
+}
+
+// waitSubscribers espera o canal ter exatamente n inscrições no miniredis
+func waitSubscribers(t *testing.T, mr *miniredis.Miniredis, channel string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(channel)[channel] != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscribers on %q = %d, want %d", channel, mr.PubSubNumSub(channel)[channel], n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Latência simulada da rede entre o cliente e o miniredis; sem ela a disputa
+// pelas conexões quase não aparece na medição
+const BENCH_NETWORK_LATENCY = 200 * time.Microsecond
+
+// BenchmarkClientSerialized usa um pool com uma única conexão, o que serializa
+// os comandos como o lock global que existia no redis_client
+//
+//	go test -run '^$' -bench Client ./pkg/adapter/redisdb
+func BenchmarkClientSerialized(b *testing.B) {
+	benchmarkClient(b, "1")
+}
+
+// BenchmarkClientConcurrent usa o pool padrão do go-redis
+func BenchmarkClientConcurrent(b *testing.B) {
+	benchmarkClient(b, "")
+}
+
+func benchmarkClient(b *testing.B, poolSize string) {
+	mr := miniredis.RunT(b)
+	host, port, _ := net.SplitHostPort(startLatencyProxy(b, mr.Addr(), BENCH_NETWORK_LATENCY))
+	b.Setenv("SRV_RDB_HOST", host)
+	b.Setenv("SRV_RDB_PORT", port)
+	b.Setenv("SRV_RDB_POOL_SIZE", poolSize)
+
+	rdb, err := NewWithError(&config.Config{RedisDBConfig: &config.RedisDBConfig{}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer rdb.Close()
+
+	ctx := context.Background()
+	data := []byte(`{"ok": "ok"}`)
+	if err := rdb.Set(ctx, "bench:key", data, time.Minute); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, parallelism := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("goroutines=%d", parallelism), func(b *testing.B) {
+			var n atomic.Int64
+
+			// RunParallel cria parallelism * GOMAXPROCS goroutines
+			b.SetParallelism(parallelism)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					key := "bench:" + strconv.FormatInt(n.Add(1)%1000, 10)
+					if err := rdb.Set(ctx, key, data, time.Minute); err != nil {
+						b.Error(err)
+					}
+					if _, err := rdb.Get(ctx, "bench:key"); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// startLatencyProxy repassa as conexões para o miniredis com um atraso em cada
+// leitura
+func startLatencyProxy(tb testing.TB, target string, latency time.Duration) string {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			client, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			server, err := net.Dial("tcp", target)
+			if err != nil {
+				client.Close()
+				continue
+			}
+
+			go pipe(client, server, latency)
+			go pipe(server, client, 0)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func pipe(src, dst net.Conn, latency time.Duration) {
+	defer src.Close()
+	defer dst.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			return
+		}
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+		if _, err := dst.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
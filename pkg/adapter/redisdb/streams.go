@@ -0,0 +1,449 @@
+package redisdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_STREAM_MAX_LEN        = 10000
+	DEFAULT_STREAM_COUNT          = 10
+	DEFAULT_STREAM_BLOCK          = 2 * time.Second
+	DEFAULT_STREAM_MIN_IDLE       = time.Minute
+	DEFAULT_STREAM_CLAIM_INTERVAL = 30 * time.Second
+	DEFAULT_STREAM_MAX_RETRIES    = 5
+	DEFAULT_STREAM_DLQ_SUFFIX     = ":dlq"
+	// STREAM_DATA_FIELD campo usado pelo Publish e pelo StreamMessage.Data
+	STREAM_DATA_FIELD = "data"
+)
+
+// StreamConfig configuração do Stream
+type StreamConfig struct {
+	// Stream nome do stream (obrigatório)
+	Stream string
+	// MaxLen tamanho aproximado máximo do stream no XADD (padrão: 10000)
+	MaxLen int64
+	// Group consumer group usado pelo Consume (obrigatório para consumir)
+	Group string
+	// GroupStartID onde o grupo começa quando é criado: "0" lê o que já está no
+	// stream, "$" apenas as novas mensagens (padrão: "0")
+	GroupStartID string
+	// Consumer nome do consumidor no grupo (padrão: hostname + id aleatório)
+	Consumer string
+	// Count mensagens lidas por XREADGROUP (padrão: 10)
+	Count int64
+	// Block tempo de espera do XREADGROUP por novas mensagens (padrão: 2s)
+	Block time.Duration
+	// Concurrency mensagens processadas ao mesmo tempo (padrão: 1)
+	Concurrency int
+	// MinIdle tempo sem ack para que uma mensagem de outro consumidor (ex: pod
+	// que caiu) ou com erro seja entregue novamente via XAUTOCLAIM (padrão: 1 minuto)
+	MinIdle time.Duration
+	// ClaimInterval intervalo entre as verificações de mensagens pendentes (padrão: 30s)
+	ClaimInterval time.Duration
+	// MaxRetries novas entregas de uma mensagem com erro antes de enviá-la para o
+	// DeadLetterStream (padrão: 5)
+	MaxRetries int64
+	// DeadLetterStream stream das mensagens que falharam (padrão: Stream + ":dlq")
+	DeadLetterStream string
+}
+
+// StreamMessage mensagem recebida no Consume
+type StreamMessage struct {
+	redis.XMessage
+	Stream string
+	// Retries quantas vezes a mensagem já foi entregue antes desta
+	Retries int64
+}
+
+// Data retorna o conteúdo enviado pelo Publish
+func (m *StreamMessage) Data() []byte {
+	switch v := m.Values[STREAM_DATA_FIELD].(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
+
+// Stream produtor e consumidor de Redis Streams. Diferente do pub/sub, as
+// mensagens ficam guardadas no stream e são entregues quando o consumidor se
+// conectar, com ack, nova entrega das mensagens sem ack e dead-letter.
+type Stream struct {
+	rdb RedisClientInterfaceV2
+	cfg *StreamConfig
+}
+
+// NewStream cria o Stream.
+//
+// Exemplo de Uso:
+//
+//	orders := redisdb.NewStream(rdb, &redisdb.StreamConfig{
+//	    Stream: "orders",
+//	    Group:  "billing",
+//	})
+//
+//	// produtor
+//	orders.Publish(ctx, []byte(`{"id": 1}`))
+//
+//	// consumidor: retornar erro mantém a mensagem pendente para nova entrega
+//	orders.Consume(ctx, func(ctx context.Context, msg *redisdb.StreamMessage) error {
+//	    return processar(ctx, msg.Data())
+//	})
+func NewStream(rdb RedisClientInterfaceV2, cfg *StreamConfig) *Stream {
+	if rdb == nil {
+		log.Fatal().Str("FunctionName", "NewStream").Msg("O cliente redisdb é obrigatório!")
+	}
+	if cfg == nil || cfg.Stream == "" {
+		log.Fatal().Str("FunctionName", "NewStream").Msg("StreamConfig.Stream é obrigatório!")
+	}
+
+	if cfg.MaxLen <= 0 {
+		cfg.MaxLen = DEFAULT_STREAM_MAX_LEN
+	}
+	if cfg.GroupStartID == "" {
+		cfg.GroupStartID = "0"
+	}
+	if cfg.Consumer == "" {
+		hostname, _ := os.Hostname()
+		cfg.Consumer = hostname + "-" + uuid.New().String()[:8]
+	}
+	if cfg.Count <= 0 {
+		cfg.Count = DEFAULT_STREAM_COUNT
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = DEFAULT_STREAM_BLOCK
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MinIdle <= 0 {
+		cfg.MinIdle = DEFAULT_STREAM_MIN_IDLE
+	}
+	if cfg.ClaimInterval <= 0 {
+		cfg.ClaimInterval = DEFAULT_STREAM_CLAIM_INTERVAL
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DEFAULT_STREAM_MAX_RETRIES
+	}
+	if cfg.DeadLetterStream == "" {
+		cfg.DeadLetterStream = cfg.Stream + DEFAULT_STREAM_DLQ_SUFFIX
+	}
+
+	return &Stream{rdb: rdb, cfg: cfg}
+}
+
+// Add adiciona uma mensagem ao stream (XADD com MAXLEN aproximado) e retorna o ID
+func (s *Stream) Add(ctx context.Context, values map[string]interface{}) (string, error) {
+	id, err := s.rdb.GetClient().XAdd(ctx, &redis.XAddArgs{
+		Stream: s.cfg.Stream,
+		MaxLen: s.cfg.MaxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return "", wrapError("xadd", s.cfg.Stream, err)
+	}
+
+	return id, nil
+}
+
+// Publish adiciona a mensagem no campo "data", como o Publish do pub/sub
+func (s *Stream) Publish(ctx context.Context, message []byte) (string, error) {
+	return s.Add(ctx, map[string]interface{}{STREAM_DATA_FIELD: message})
+}
+
+// Consume lê as mensagens do consumer group até o ctx ser cancelado, chamando o
+// callback para cada uma. Quando o callback retorna nil a mensagem recebe ack;
+// com erro ela continua pendente e é entregue novamente depois de MinIdle. Depois
+// de MaxRetries novas entregas a mensagem vai para o DeadLetterStream.
+//
+// Mensagens pendentes de consumidores que pararam (ex: pod que caiu) também são
+// reivindicadas com XAUTOCLAIM a cada ClaimInterval.
+func (s *Stream) Consume(ctx context.Context, callback func(ctx context.Context, msg *StreamMessage) error) error {
+	if s.cfg.Group == "" {
+		return errors.New("redisdb: StreamConfig.Group is required to consume")
+	}
+
+	client := s.rdb.GetClient()
+
+	err := client.XGroupCreateMkStream(ctx, s.cfg.Stream, s.cfg.Group, s.cfg.GroupStartID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return wrapError("xgroup", s.cfg.Stream, err)
+	}
+
+	log.Info().Str("Stream", s.cfg.Stream).Str("Group", s.cfg.Group).Str("Consumer", s.cfg.Consumer).Msg("Stream consumer started")
+	defer s.removeConsumer()
+
+	var lastClaim time.Time
+
+	for {
+		if ctx.Err() != nil {
+			log.Info().Str("Stream", s.cfg.Stream).Str("Group", s.cfg.Group).Str("Consumer", s.cfg.Consumer).Msg("Stream consumer stopped")
+			return nil
+		}
+
+		if time.Since(lastClaim) >= s.cfg.ClaimInterval {
+			s.claim(ctx, callback)
+			lastClaim = time.Now()
+		}
+
+		streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.cfg.Group,
+			Consumer: s.cfg.Consumer,
+			Streams:  []string{s.cfg.Stream, ">"},
+			Count:    s.cfg.Count,
+			Block:    s.cfg.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			log.Error().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Msg(err.Error())
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			s.handle(ctx, stream.Messages, nil, callback)
+		}
+	}
+}
+
+// claim reivindica as mensagens pendentes há mais de MinIdle
+func (s *Stream) claim(ctx context.Context, callback func(ctx context.Context, msg *StreamMessage) error) {
+	start := "0-0"
+
+	for ctx.Err() == nil {
+		messages, next, deleted, err := s.autoClaim(ctx, start)
+		if err != nil {
+			log.Error().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Msg(err.Error())
+			return
+		}
+
+		// Mensagens removidas do stream (XDEL/MAXLEN) nunca serão processadas;
+		// sem o ack elas ficariam para sempre na lista de pendentes
+		if len(deleted) > 0 {
+			s.ack(ctx, deleted...)
+		}
+
+		if len(messages) > 0 {
+			s.handle(ctx, messages, s.deliveryCounts(ctx, messages), callback)
+		}
+
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// autoClaim executa o XAUTOCLAIM sem o parser do go-redis v8, que não aceita a
+// resposta com três elementos do Redis 7
+func (s *Stream) autoClaim(ctx context.Context, start string) ([]redis.XMessage, string, []string, error) {
+	reply, err := s.rdb.GetClient().Do(ctx, "XAUTOCLAIM", s.cfg.Stream, s.cfg.Group, s.cfg.Consumer,
+		s.cfg.MinIdle.Milliseconds(), start, "COUNT", s.cfg.Count).Result()
+	if err != nil {
+		return nil, "", nil, wrapError("xautoclaim", s.cfg.Stream, err)
+	}
+
+	return parseAutoClaim(reply)
+}
+
+// parseAutoClaim separa as mensagens reivindicadas, o próximo ID e os IDs das
+// mensagens que não existem mais no stream
+func parseAutoClaim(reply interface{}) ([]redis.XMessage, string, []string, error) {
+	parts, ok := reply.([]interface{})
+	if !ok || len(parts) < 2 {
+		return nil, "", nil, fmt.Errorf("redisdb: unexpected XAUTOCLAIM reply %v", reply)
+	}
+
+	next, _ := parts[0].(string)
+	entries, _ := parts[1].([]interface{})
+
+	var deleted []string
+	// Redis 7: terceiro elemento com os IDs removidos do stream
+	if len(parts) > 2 {
+		ids, _ := parts[2].([]interface{})
+		for _, id := range ids {
+			if id, ok := id.(string); ok {
+				deleted = append(deleted, id)
+			}
+		}
+	}
+
+	messages := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) != 2 {
+			continue
+		}
+
+		id, _ := fields[0].(string)
+		kv, ok := fields[1].([]interface{})
+		// Redis 6.2: entradas removidas do stream chegam sem campos
+		if !ok {
+			deleted = append(deleted, id)
+			continue
+		}
+
+		values := make(map[string]interface{}, len(kv)/2)
+		for i := 0; i+1 < len(kv); i += 2 {
+			key, _ := kv[i].(string)
+			values[key] = kv[i+1]
+		}
+
+		messages = append(messages, redis.XMessage{ID: id, Values: values})
+	}
+
+	return messages, next, deleted, nil
+}
+
+// deliveryCounts consulta no XPENDING quantas vezes cada mensagem foi entregue
+func (s *Stream) deliveryCounts(ctx context.Context, messages []redis.XMessage) map[string]int64 {
+	cmds := make([]*redis.XPendingExtCmd, len(messages))
+
+	_, err := s.rdb.GetClient().Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, msg := range messages {
+			cmds[i] = pipe.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: s.cfg.Stream,
+				Group:  s.cfg.Group,
+				Start:  msg.ID,
+				End:    msg.ID,
+				Count:  1,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Msg(err.Error())
+	}
+
+	counts := make(map[string]int64, len(messages))
+	for _, cmd := range cmds {
+		for _, pending := range cmd.Val() {
+			counts[pending.ID] = pending.RetryCount
+		}
+	}
+
+	return counts
+}
+
+// handle processa as mensagens respeitando Concurrency. deliveries é nil para
+// mensagens novas (primeira entrega).
+func (s *Stream) handle(ctx context.Context, messages []redis.XMessage, deliveries map[string]int64, callback func(ctx context.Context, msg *StreamMessage) error) {
+	sem := make(chan struct{}, s.cfg.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, m := range messages {
+		msg := &StreamMessage{XMessage: m, Stream: s.cfg.Stream}
+		if count, ok := deliveries[m.ID]; ok && count > 0 {
+			msg.Retries = count - 1
+		}
+
+		if msg.Retries > s.cfg.MaxRetries {
+			s.deadLetter(ctx, msg)
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := callback(ctx, msg); err != nil {
+				log.Warn().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Str("ID", msg.ID).Int64("Retries", msg.Retries).Msg(err.Error())
+				return
+			}
+
+			s.ack(ctx, msg.ID)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// ack confirma as mensagens mesmo se o ctx do Consume for cancelado durante o
+// processamento, evitando que uma mensagem já processada seja entregue de novo
+func (s *Stream) ack(ctx context.Context, ids ...string) bool {
+	if err := s.rdb.GetClient().XAck(context.WithoutCancel(ctx), s.cfg.Stream, s.cfg.Group, ids...).Err(); err != nil {
+		log.Error().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Strs("ID", ids).Msg(err.Error())
+		return false
+	}
+
+	return true
+}
+
+// deadLetter copia a mensagem para o DeadLetterStream e faz o ack no original
+func (s *Stream) deadLetter(ctx context.Context, msg *StreamMessage) {
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values["dlq_stream"] = s.cfg.Stream
+	values["dlq_group"] = s.cfg.Group
+	values["dlq_id"] = msg.ID
+	values["dlq_retries"] = strconv.FormatInt(msg.Retries, 10)
+
+	client := s.rdb.GetClient()
+
+	err := client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.cfg.DeadLetterStream,
+		MaxLen: s.cfg.MaxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+	if err != nil {
+		log.Error().Str("FunctionName", "Consume").Str("Stream", s.cfg.DeadLetterStream).Str("ID", msg.ID).Msg(err.Error())
+		return
+	}
+
+	if !s.ack(ctx, msg.ID) {
+		return
+	}
+
+	log.Warn().Str("FunctionName", "Consume").Str("Stream", s.cfg.Stream).Str("ID", msg.ID).Str("DeadLetterStream", s.cfg.DeadLetterStream).Msg("Mensagem enviada para o dead-letter")
+}
+
+// removeConsumer remove o consumidor do grupo se ele não tiver mensagens pendentes
+func (s *Stream) removeConsumer() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := s.rdb.GetClient()
+
+	pending, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   s.cfg.Stream,
+		Group:    s.cfg.Group,
+		Start:    "-",
+		End:      "+",
+		Count:    1,
+		Consumer: s.cfg.Consumer,
+	}).Result()
+	if err != nil || len(pending) > 0 {
+		return
+	}
+
+	client.XGroupDelConsumer(ctx, s.cfg.Stream, s.cfg.Group, s.cfg.Consumer)
+}
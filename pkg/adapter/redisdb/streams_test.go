@@ -0,0 +1,225 @@
+package redisdb
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestStream(t *testing.T, cfg *StreamConfig) (*Stream, *redis_client) {
+	t.Helper()
+
+	rs, _ := newTestClient(t)
+	if cfg.Stream == "" {
+		cfg.Stream = "orders"
+	}
+	if cfg.Group == "" {
+		cfg.Group = "billing"
+	}
+	cfg.Block = 20 * time.Millisecond
+
+	return NewStream(rs, cfg), rs
+}
+
+// consume executa o Consume em background até o teste terminar
+func consume(t *testing.T, s *Stream, callback func(ctx context.Context, msg *StreamMessage) error) context.CancelFunc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Consume(ctx, callback) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Consume err = %v", err)
+		}
+	})
+
+	return cancel
+}
+
+func pendingCount(t *testing.T, s *Stream) int64 {
+	t.Helper()
+
+	pending, err := s.rdb.GetClient().XPending(context.Background(), s.cfg.Stream, s.cfg.Group).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pending.Count
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamConsume(t *testing.T) {
+	s, _ := newTestStream(t, &StreamConfig{Concurrency: 2})
+	ctx := context.Background()
+
+	for _, data := range []string{"a", "b", "c"} {
+		if _, err := s.Publish(ctx, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	received := map[string]int64{}
+	consume(t, s, func(ctx context.Context, msg *StreamMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[string(msg.Data())] = msg.Retries
+		return nil
+	})
+
+	waitFor(t, "messages", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	})
+	waitFor(t, "acks", func() bool { return pendingCount(t, s) == 0 })
+
+	if !reflect.DeepEqual(received, map[string]int64{"a": 0, "b": 0, "c": 0}) {
+		t.Errorf("received = %v", received)
+	}
+}
+
+func TestStreamConsumeWithoutGroup(t *testing.T) {
+	rs, _ := newTestClient(t)
+	s := NewStream(rs, &StreamConfig{Stream: "orders"})
+
+	if err := s.Consume(context.Background(), func(ctx context.Context, msg *StreamMessage) error { return nil }); err == nil {
+		t.Error("expected error without Group")
+	}
+}
+
+func TestStreamRetryAndDeadLetter(t *testing.T) {
+	s, rs := newTestStream(t, &StreamConfig{
+		MinIdle:       10 * time.Millisecond,
+		ClaimInterval: 10 * time.Millisecond,
+		MaxRetries:    2,
+	})
+	ctx := context.Background()
+
+	id, err := s.Publish(ctx, []byte("fail"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var retries []int64
+	consume(t, s, func(ctx context.Context, msg *StreamMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		retries = append(retries, msg.Retries)
+		return context.DeadlineExceeded
+	})
+
+	var dlq []redis.XMessage
+	waitFor(t, "dead-letter", func() bool {
+		dlq, _ = rs.rdb.XRange(ctx, "orders:dlq", "-", "+").Result()
+		return len(dlq) == 1
+	})
+	waitFor(t, "ack of the original", func() bool { return pendingCount(t, s) == 0 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(retries, []int64{0, 1, 2}) {
+		t.Errorf("retries = %v, want [0 1 2]", retries)
+	}
+	values := dlq[0].Values
+	if values[STREAM_DATA_FIELD] != "fail" || values["dlq_id"] != id || values["dlq_retries"] != "3" || values["dlq_group"] != "billing" {
+		t.Errorf("dlq = %v", values)
+	}
+}
+
+func TestStreamAckAfterCancel(t *testing.T) {
+	s, _ := newTestStream(t, &StreamConfig{})
+	ctx := context.Background()
+
+	if _, err := s.Publish(ctx, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelCh := make(chan context.CancelFunc, 1)
+	processed := make(chan struct{})
+	cancelCh <- consume(t, s, func(ctx context.Context, msg *StreamMessage) error {
+		// O Consume é cancelado enquanto a mensagem é processada
+		(<-cancelCh)()
+		close(processed)
+		return nil
+	})
+
+	<-processed
+	waitFor(t, "ack", func() bool { return pendingCount(t, s) == 0 })
+}
+
+func TestParseAutoClaim(t *testing.T) {
+	entry := []interface{}{"1-0", []interface{}{"data", "a"}}
+
+	tests := []struct {
+		name        string
+		reply       interface{}
+		wantIDs     []string
+		wantNext    string
+		wantDeleted []string
+		wantErr     bool
+	}{
+		{
+			name:     "redis 6.2",
+			reply:    []interface{}{"0-0", []interface{}{entry}},
+			wantIDs:  []string{"1-0"},
+			wantNext: "0-0",
+		},
+		{
+			name:        "redis 6.2 deleted entry",
+			reply:       []interface{}{"3-0", []interface{}{entry, []interface{}{"2-0", nil}}},
+			wantIDs:     []string{"1-0"},
+			wantNext:    "3-0",
+			wantDeleted: []string{"2-0"},
+		},
+		{
+			name:        "redis 7 deleted ids",
+			reply:       []interface{}{"0-0", []interface{}{entry}, []interface{}{"2-0", "3-0"}},
+			wantIDs:     []string{"1-0"},
+			wantNext:    "0-0",
+			wantDeleted: []string{"2-0", "3-0"},
+		},
+		{name: "invalid", reply: "OK", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, next, deleted, err := parseAutoClaim(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var ids []string
+			for _, msg := range messages {
+				ids = append(ids, msg.ID)
+				if msg.Values["data"] != "a" {
+					t.Errorf("values = %v", msg.Values)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || next != tt.wantNext || !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("ids = %v, next = %q, deleted = %v", ids, next, deleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	gws "github.com/gorilla/websocket"
+	"github.com/phuslu/log"
+)
+
+// Conn conexão WebSocket registrada no hub
+type Conn struct {
+	ID      string
+	Request *http.Request
+
+	hub  *hub
+	ws   *gws.Conn
+	send chan []byte
+	done chan struct{}
+
+	mu    sync.Mutex
+	rooms map[string]struct{}
+
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// Send coloca a mensagem no buffer de envio da conexão sem bloquear.
+//
+// Retorna ErrSendBufferFull quando o cliente não está consumindo as mensagens
+// na mesma velocidade em que são enviadas e ErrConnClosed se a conexão já foi
+// encerrada.
+func (c *Conn) Send(data []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return nil
+	default:
+		return ErrSendBufferFull
+	}
+}
+
+// Rooms retorna as salas em que a conexão está
+func (c *Conn) Rooms() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Close encerra a conexão com CloseNormalClosure
+func (c *Conn) Close() {
+	c.CloseWithReason(gws.CloseNormalClosure, "")
+}
+
+// CloseWithReason encerra a conexão enviando o código e o motivo ao cliente
+func (c *Conn) CloseWithReason(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+		c.hub.unregister(c)
+	})
+}
+
+func (c *Conn) pingInterval() time.Duration {
+	return time.Duration(c.hub.conf.WS_PING_INTERVAL) * time.Second
+}
+
+func (c *Conn) readPump() {
+	defer c.hub.wg.Done()
+	defer c.Close()
+
+	// O cliente tem até dois intervalos de ping para responder com pong
+	pongWait := 2 * c.pingInterval()
+
+	c.ws.SetReadLimit(c.hub.conf.WS_MAX_MESSAGE_SIZE)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if gws.IsUnexpectedCloseError(err, gws.CloseNormalClosure, gws.CloseGoingAway, gws.CloseNoStatusReceived) {
+				log.Warn().Str("FunctionName", "readPump").Str("ConnID", c.ID).Msg(err.Error())
+			}
+			return
+		}
+
+		if c.hub.onMessage != nil {
+			c.hub.onMessage(c, data)
+		}
+	}
+}
+
+func (c *Conn) writePump() {
+	ticker := time.NewTicker(c.pingInterval())
+
+	defer func() {
+		ticker.Stop()
+		c.ws.Close()
+		c.hub.wg.Done()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(DEFAULT_WS_WRITE_WAIT))
+			if err := c.ws.WriteMessage(gws.TextMessage, data); err != nil {
+				log.Warn().Str("FunctionName", "writePump").Str("ConnID", c.ID).Msg(err.Error())
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(gws.PingMessage, nil, time.Now().Add(DEFAULT_WS_WRITE_WAIT)); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			msg := gws.FormatCloseMessage(c.closeCode, c.closeReason)
+			c.ws.WriteControl(gws.CloseMessage, msg, time.Now().Add(DEFAULT_WS_WRITE_WAIT))
+			return
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/faelp22/go-commons-libs/pkg/adapter/rabbitmq"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+	"github.com/google/uuid"
+	"github.com/phuslu/log"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	RELAY_MESSAGE_KIND = "ws-relay"
+	// DEFAULT_WS_RELAY_CHANNEL canal do Redis usado pelo NewRedisRelay
+	DEFAULT_WS_RELAY_CHANNEL = "ws:relay"
+)
+
+// RelayMessage mensagem trocada entre as instâncias.
+//
+// Room vazio significa Broadcast para todas as conexões.
+type RelayMessage struct {
+	Kind       string `json:"kind"`
+	InstanceID string `json:"instance_id"`
+	Room       string `json:"room,omitempty"`
+	Data       []byte `json:"data"`
+}
+
+// Relay distribui as mensagens de Broadcast/BroadcastRoom entre as réplicas
+// da aplicação, permitindo que clientes conectados em outros pods recebam as
+// mensagens.
+type Relay interface {
+	// Publish envia a mensagem para as outras instâncias
+	Publish(ctx context.Context, msg *RelayMessage) error
+	// Start começa a receber mensagens das outras instâncias
+	Start(handler func(msg *RelayMessage)) error
+	// Close para de receber mensagens
+	Close() error
+}
+
+func decodeRelayMessage(data []byte) (*RelayMessage, bool) {
+	var msg RelayMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Kind != RELAY_MESSAGE_KIND {
+		return nil, false
+	}
+	return &msg, true
+}
+
+type redisRelay struct {
+	rdb     redisdb.RedisClientInterface
+	channel string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRedisRelay cria um Relay usando o pub/sub do redisdb em um canal próprio
+// (padrão: DEFAULT_WS_RELAY_CHANNEL), separado do SRV_RDB_PUBSUB_CHANNEL da
+// aplicação. As mensagens são entregues ao hub na ordem em que foram publicadas.
+//
+// Exemplo de Uso:
+//
+//	hub := websocket.New(conf)
+//	hub.SetRelay(websocket.NewRedisRelay(redisConn, ""))
+func NewRedisRelay(rdb redisdb.RedisClientInterface, channel string) Relay {
+	if channel == "" {
+		channel = DEFAULT_WS_RELAY_CHANNEL
+	}
+	return &redisRelay{rdb: rdb, channel: channel}
+}
+
+func (rr *redisRelay) Publish(ctx context.Context, msg *RelayMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return rr.rdb.GetClient().Publish(ctx, rr.channel, data).Err()
+}
+
+func (rr *redisRelay) Start(handler func(msg *RelayMessage)) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if rr.cancel != nil {
+		return errors.New("websocket: relay already started")
+	}
+
+	// O go-redis refaz a inscrição sozinho se a conexão cair
+	ctx, cancel := context.WithCancel(context.Background())
+	pubsub := rr.rdb.GetClient().Subscribe(ctx, rr.channel)
+	rr.cancel = cancel
+	rr.done = make(chan struct{})
+
+	go func() {
+		defer close(rr.done)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-ch:
+				if !ok {
+					return
+				}
+				if msg, ok := decodeRelayMessage([]byte(m.Payload)); ok {
+					handler(msg)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close encerra a inscrição e aguarda a goroutine de leitura terminar
+func (rr *redisRelay) Close() error {
+	rr.mu.Lock()
+	cancel, done := rr.cancel, rr.done
+	rr.cancel = nil
+	rr.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+type rabbitMQRelay struct {
+	rbmq        rabbitmq.RabbitInterface
+	exchange    string
+	consumerTag string
+	closed      atomic.Bool
+}
+
+// NewRabbitMQRelay cria um Relay usando um exchange fanout do RabbitMQ. Cada
+// instância declara uma fila exclusiva ligada ao exchange. A conexão precisa
+// ter sido aberta com Connect antes de chamar SetRelay.
+//
+// Exemplo de Uso:
+//
+//	rbmqConn, _ := rabbitmq.New(conf).Connect()
+//	hub.SetRelay(websocket.NewRabbitMQRelay(rbmqConn, "ws.relay"))
+func NewRabbitMQRelay(rbmq rabbitmq.RabbitInterface, exchange string) Relay {
+	return &rabbitMQRelay{
+		rbmq:        rbmq,
+		exchange:    exchange,
+		consumerTag: "ws-relay-" + uuid.New().String(),
+	}
+}
+
+func (rr *rabbitMQRelay) Publish(ctx context.Context, msg *RelayMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return rr.rbmq.Producer(ctx, &rabbitmq.ProducerConfig{Exchange: rr.exchange}, &rabbitmq.Message{
+		Data:        data,
+		ContentType: "application/json",
+	})
+}
+
+func (rr *rabbitMQRelay) Start(handler func(msg *RelayMessage)) error {
+	if err := rr.rbmq.SimpleExchangeDeclare(rabbitmq.Exchange{
+		Name: rr.exchange,
+		Kind: amqp.ExchangeFanout,
+	}); err != nil {
+		return err
+	}
+
+	queue, err := rr.rbmq.SimpleQueueDeclare(rabbitmq.Queue{
+		Exclusive:  true,
+		AutoDelete: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	channel := rr.rbmq.GetAmqpChannel()
+	if err := channel.QueueBind(queue.Name, "", rr.exchange, false, nil); err != nil {
+		log.Error().Str("FunctionName", "rabbitMQRelay.Start").Str("Erro", err.Error()).Msg("Erro to QueueBind in RabbitMQ")
+		return err
+	}
+
+	// O Consume é chamado direto porque o rabbitmq.Consumer apenas loga a falha
+	// ao registrar o consumer, e sem ele o relay ficaria mudo
+	deliveries, err := channel.Consume(queue.Name, rr.consumerTag, true, true, false, false, nil)
+	if err != nil {
+		log.Error().Str("FunctionName", "rabbitMQRelay.Start").Str("Erro", err.Error()).Msg("Erro to register the consumer in RabbitMQ")
+		return err
+	}
+
+	go func() {
+		for d := range deliveries {
+			if rr.closed.Load() {
+				continue
+			}
+			if msg, ok := decodeRelayMessage(d.Body); ok {
+				handler(msg)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close cancela o consumer; a fila exclusiva é removida pelo RabbitMQ
+func (rr *rabbitMQRelay) Close() error {
+	if rr.closed.Swap(true) {
+		return nil
+	}
+
+	channel := rr.rbmq.GetAmqpChannel()
+	if channel == nil || channel.IsClosed() {
+		return nil
+	}
+
+	return channel.Cancel(rr.consumerTag, false)
+}
@@ -0,0 +1,159 @@
+package websocket
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/redisdb"
+)
+
+func newTestRedis(t *testing.T, mr *miniredis.Miniredis) redisdb.RedisClientInterface {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(mr.Addr())
+	t.Setenv("SRV_RDB_HOST", host)
+	t.Setenv("SRV_RDB_PORT", port)
+
+	conf := config.NewDefaultConf()
+	conf.SetAppLogLevel("error")
+	if conf.RedisDBConfig == nil {
+		conf.RedisDBConfig = &config.RedisDBConfig{}
+	}
+
+	rdb, err := redisdb.NewWithError(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rdb.Close() })
+
+	return rdb.(redisdb.RedisClientInterface)
+}
+
+func TestRedisRelay(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	hubA, _ := newTestHub(t)
+	hubB, srvB := newTestHub(t)
+
+	relayA := NewRedisRelay(newTestRedis(t, mr), "")
+	relayB := NewRedisRelay(newTestRedis(t, mr), "")
+	if err := hubA.SetRelay(relayA); err != nil {
+		t.Fatal(err)
+	}
+	if err := hubB.SetRelay(relayB); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return mr.PubSubNumSub(DEFAULT_WS_RELAY_CHANNEL)[DEFAULT_WS_RELAY_CHANNEL] == 2 })
+
+	clientB := dial(t, srvB, "room=chat")
+	waitFor(t, func() bool { return hubB.RoomCount("chat") == 1 })
+
+	// A ordem das mensagens é mantida entre as instâncias
+	for i := 0; i < 50; i++ {
+		hubA.BroadcastRoom("chat", []byte(strconv.Itoa(i)))
+	}
+	for i := 0; i < 50; i++ {
+		if got := readText(t, clientB); got != strconv.Itoa(i) {
+			t.Fatalf("message %d = %q", i, got)
+		}
+	}
+
+	// Mensagens publicadas pela própria instância não são entregues de novo
+	hubB.Broadcast([]byte("local"))
+	if got := readText(t, clientB); got != "local" {
+		t.Fatalf("got %q", got)
+	}
+	clientB.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, data, err := clientB.ReadMessage(); err == nil {
+		t.Fatalf("duplicated message %q", data)
+	}
+
+	if err := relayA.Close(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return mr.PubSubNumSub(DEFAULT_WS_RELAY_CHANNEL)[DEFAULT_WS_RELAY_CHANNEL] == 1 })
+
+	// Close é idempotente e o Shutdown do hub encerra o relay
+	if err := relayA.Close(); err != nil {
+		t.Fatal(err)
+	}
+	hubB.Close()
+	waitFor(t, func() bool { return mr.PubSubNumSub(DEFAULT_WS_RELAY_CHANNEL)[DEFAULT_WS_RELAY_CHANNEL] == 0 })
+}
+
+func TestRedisRelayIgnoresOtherMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := newTestRedis(t, mr)
+
+	received := make(chan *RelayMessage, 10)
+	relay := NewRedisRelay(rdb, "custom")
+	if err := relay.Start(func(msg *RelayMessage) { received <- msg }); err != nil {
+		t.Fatal(err)
+	}
+	defer relay.Close()
+	if err := relay.Start(func(msg *RelayMessage) {}); err == nil {
+		t.Fatal("second Start should fail")
+	}
+	waitFor(t, func() bool { return mr.PubSubNumSub("custom")["custom"] == 1 })
+
+	ctx := context.Background()
+	rdb.GetClient().Publish(ctx, "custom", `{"other":"payload"}`)
+	rdb.GetClient().Publish(ctx, "custom", "not json")
+	relay.Publish(ctx, &RelayMessage{Kind: RELAY_MESSAGE_KIND, InstanceID: "x", Room: "r", Data: []byte("ok")})
+
+	select {
+	case msg := <-received:
+		if msg.Room != "r" || string(msg.Data) != "ok" {
+			t.Fatalf("msg = %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("relay message not received")
+	}
+	if len(received) != 0 {
+		t.Fatalf("unexpected messages: %d", len(received))
+	}
+}
+
+// fakeRelay liga hubs na memória para testar o tratamento do InstanceID
+type fakeRelay struct {
+	handlers []func(msg *RelayMessage)
+}
+
+func (f *fakeRelay) Publish(ctx context.Context, msg *RelayMessage) error {
+	for _, h := range f.handlers {
+		h(msg)
+	}
+	return nil
+}
+
+func (f *fakeRelay) Start(handler func(msg *RelayMessage)) error {
+	f.handlers = append(f.handlers, handler)
+	return nil
+}
+
+func (f *fakeRelay) Close() error { return nil }
+
+func TestHubRelayEcho(t *testing.T) {
+	h, srv := newTestHub(t)
+	relay := &fakeRelay{}
+	h.SetRelay(relay)
+
+	ws := dial(t, srv, "")
+	waitFor(t, func() bool { return h.Count() == 1 })
+
+	h.Broadcast([]byte("once"))
+	if got := readText(t, ws); got != "once" {
+		t.Fatalf("got %q", got)
+	}
+
+	// Mensagem de outra instância é entregue localmente
+	relay.Publish(context.Background(), &RelayMessage{Kind: RELAY_MESSAGE_KIND, InstanceID: "other", Data: []byte("remote")})
+	if got := readText(t, ws); got != "remote" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,485 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	"github.com/google/uuid"
+	gws "github.com/gorilla/websocket"
+	"github.com/phuslu/log"
+)
+
+const (
+	DEFAULT_WS_SEND_BUFFER_SIZE = 256       // mensagens
+	DEFAULT_WS_PING_INTERVAL    = 30        // segundos
+	DEFAULT_WS_MAX_MESSAGE_SIZE = 64 * 1024 // bytes
+	DEFAULT_WS_WRITE_WAIT       = time.Second * 10
+)
+
+var (
+	ErrConnClosed     = errors.New("websocket connection closed")
+	ErrSendBufferFull = errors.New("websocket send buffer full")
+)
+
+type HubInterface interface {
+	// ServeHTTP faz o upgrade da requisição e registra a conexão no hub
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+	// OnConnect define o callback chamado quando uma conexão é registrada
+	OnConnect(callback func(c *Conn))
+	// OnMessage define o callback chamado para cada mensagem recebida
+	OnMessage(callback func(c *Conn, data []byte))
+	// OnDisconnect define o callback chamado quando uma conexão é encerrada
+	OnDisconnect(callback func(c *Conn))
+	// Join adiciona a conexão em uma sala
+	Join(c *Conn, room string)
+	// Leave remove a conexão de uma sala
+	Leave(c *Conn, room string)
+	// Broadcast envia a mensagem para todas as conexões
+	Broadcast(data []byte)
+	// BroadcastRoom envia a mensagem para todas as conexões da sala
+	BroadcastRoom(room string, data []byte)
+	// GetConn retorna a conexão pelo ID
+	GetConn(id string) (*Conn, bool)
+	// Count retorna a quantidade de conexões ativas
+	Count() int
+	// RoomCount retorna a quantidade de conexões na sala
+	RoomCount(room string) int
+	// Shutdown fecha todas as conexões com CloseGoingAway e aguarda o encerramento
+	Shutdown(ctx context.Context) error
+	// Close é um atalho para Shutdown compatível com http.Server.RegisterOnShutdown
+	Close()
+	// SetRelay replica Broadcast e BroadcastRoom para as outras instâncias
+	SetRelay(relay Relay) error
+	// InstanceID identificador desta instância usado nas mensagens do relay
+	InstanceID() string
+}
+
+type hub struct {
+	conf     *config.WSConfig
+	upgrader gws.Upgrader
+
+	mu    sync.RWMutex
+	conns map[string]*Conn
+	rooms map[string]map[string]*Conn
+
+	onConnect    func(c *Conn)
+	onMessage    func(c *Conn, data []byte)
+	onDisconnect func(c *Conn)
+
+	closing bool
+	wg      sync.WaitGroup
+
+	instanceID string
+	relay      Relay
+}
+
+// New cria um hub de WebSocket.
+//
+// Variáveis de ambiente:
+//
+//	SRV_WS_ALLOWED_ORIGINS   origens permitidas separadas por vírgula ("*" libera todas).
+//	                         Quando vazio apenas requisições da mesma origem são aceitas.
+//	SRV_WS_SEND_BUFFER_SIZE  tamanho do buffer de envio por conexão (padrão: 256 mensagens)
+//	SRV_WS_PING_INTERVAL     intervalo do ping em segundos (padrão: 30)
+//	SRV_WS_MAX_MESSAGE_SIZE  tamanho máximo de uma mensagem recebida em bytes (padrão: 64KiB)
+//
+// Exemplo de Uso:
+//
+//	hub := websocket.New(conf)
+//	hub.OnMessage(func(c *websocket.Conn, data []byte) {
+//	    hub.BroadcastRoom("chat", data)
+//	})
+//	router.Handle("/ws", hub)
+//
+//	srv := httpserver.New(router, conf, nil)
+//	srv.RegisterOnShutdown(hub.Close)
+func New(conf *config.Config) HubInterface {
+	if conf.WSConfig == nil {
+		conf.WSConfig = &config.WSConfig{}
+	}
+
+	SRV_WS_ALLOWED_ORIGINS := os.Getenv("SRV_WS_ALLOWED_ORIGINS")
+	if SRV_WS_ALLOWED_ORIGINS != "" {
+		conf.WS_ALLOWED_ORIGINS = nil
+		for _, origin := range strings.Split(SRV_WS_ALLOWED_ORIGINS, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				conf.WS_ALLOWED_ORIGINS = append(conf.WS_ALLOWED_ORIGINS, origin)
+			}
+		}
+	}
+
+	SRV_WS_SEND_BUFFER_SIZE := os.Getenv("SRV_WS_SEND_BUFFER_SIZE")
+	if SRV_WS_SEND_BUFFER_SIZE != "" {
+		var err error
+		conf.WS_SEND_BUFFER_SIZE, err = strconv.Atoi(SRV_WS_SEND_BUFFER_SIZE)
+		if err != nil {
+			log.Error().Str("SRV_WS_SEND_BUFFER_SIZE", "Invalid value").Str("SetDefaultValue", "256").Msg(err.Error())
+			conf.WS_SEND_BUFFER_SIZE = DEFAULT_WS_SEND_BUFFER_SIZE
+		}
+	}
+	// Com buffer zero todo envio falharia com ErrSendBufferFull; negativo faz o
+	// make(chan) entrar em pânico
+	if conf.WS_SEND_BUFFER_SIZE <= 0 {
+		conf.WS_SEND_BUFFER_SIZE = DEFAULT_WS_SEND_BUFFER_SIZE
+	}
+
+	SRV_WS_PING_INTERVAL := os.Getenv("SRV_WS_PING_INTERVAL")
+	if SRV_WS_PING_INTERVAL != "" {
+		var err error
+		conf.WS_PING_INTERVAL, err = strconv.Atoi(SRV_WS_PING_INTERVAL)
+		if err != nil {
+			log.Error().Str("SRV_WS_PING_INTERVAL", "Invalid value").Str("SetDefaultValue", "30s").Msg(err.Error())
+			conf.WS_PING_INTERVAL = DEFAULT_WS_PING_INTERVAL
+		}
+	}
+	// time.NewTicker entra em pânico com intervalo zero ou negativo
+	if conf.WS_PING_INTERVAL <= 0 {
+		conf.WS_PING_INTERVAL = DEFAULT_WS_PING_INTERVAL
+	}
+
+	SRV_WS_MAX_MESSAGE_SIZE := os.Getenv("SRV_WS_MAX_MESSAGE_SIZE")
+	if SRV_WS_MAX_MESSAGE_SIZE != "" {
+		var err error
+		conf.WS_MAX_MESSAGE_SIZE, err = strconv.ParseInt(SRV_WS_MAX_MESSAGE_SIZE, 10, 64)
+		if err != nil {
+			log.Error().Str("SRV_WS_MAX_MESSAGE_SIZE", "Invalid value").Str("SetDefaultValue", "64KiB").Msg(err.Error())
+			conf.WS_MAX_MESSAGE_SIZE = DEFAULT_WS_MAX_MESSAGE_SIZE
+		}
+	}
+	// SetReadLimit com zero ou negativo deixaria as mensagens recebidas sem limite de tamanho
+	if conf.WS_MAX_MESSAGE_SIZE <= 0 {
+		conf.WS_MAX_MESSAGE_SIZE = DEFAULT_WS_MAX_MESSAGE_SIZE
+	}
+
+	h := &hub{
+		conf:       conf.WSConfig,
+		conns:      map[string]*Conn{},
+		rooms:      map[string]map[string]*Conn{},
+		instanceID: uuid.New().String(),
+	}
+
+	h.upgrader = gws.Upgrader{
+		CheckOrigin: h.checkOrigin,
+	}
+
+	return h
+}
+
+func (h *hub) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if len(h.conf.WS_ALLOWED_ORIGINS) == 0 {
+		return sameOrigin(r, origin)
+	}
+
+	for _, allowed := range h.conf.WS_ALLOWED_ORIGINS {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	log.Warn().Str("FunctionName", "checkOrigin").Str("Origin", origin).Msg("WebSocket origin not allowed")
+	return false
+}
+
+func sameOrigin(r *http.Request, origin string) bool {
+	_, host, found := strings.Cut(origin, "://")
+	return found && strings.EqualFold(host, r.Host)
+}
+
+func (h *hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	closing := h.closing
+	h.mu.RUnlock()
+
+	if closing {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// O upgrader já respondeu ao cliente com o erro
+		log.Error().Str("FunctionName", "ServeHTTP").Str("ERRO_WS", "Erro ao fazer o upgrade da conexão").Msg(err.Error())
+		return
+	}
+
+	c := &Conn{
+		ID:      uuid.New().String(),
+		Request: r,
+		hub:     h,
+		ws:      ws,
+		send:    make(chan []byte, h.conf.WS_SEND_BUFFER_SIZE),
+		done:    make(chan struct{}),
+		rooms:   map[string]struct{}{},
+	}
+
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		ws.WriteControl(gws.CloseMessage, gws.FormatCloseMessage(gws.CloseGoingAway, "server shutdown"), time.Now().Add(DEFAULT_WS_WRITE_WAIT))
+		ws.Close()
+		return
+	}
+	h.conns[c.ID] = c
+	h.wg.Add(2)
+	h.mu.Unlock()
+
+	if h.onConnect != nil {
+		h.onConnect(c)
+	}
+
+	go c.writePump()
+	go c.readPump()
+}
+
+func (h *hub) OnConnect(callback func(c *Conn)) {
+	h.onConnect = callback
+}
+
+func (h *hub) OnMessage(callback func(c *Conn, data []byte)) {
+	h.onMessage = callback
+}
+
+func (h *hub) OnDisconnect(callback func(c *Conn)) {
+	h.onDisconnect = callback
+}
+
+func (h *hub) Join(c *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.conns[c.ID]; !ok {
+		return
+	}
+
+	if h.rooms[room] == nil {
+		h.rooms[room] = map[string]*Conn{}
+	}
+	h.rooms[room][c.ID] = c
+
+	c.mu.Lock()
+	c.rooms[room] = struct{}{}
+	c.mu.Unlock()
+}
+
+func (h *hub) Leave(c *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.leaveLocked(c, room)
+}
+
+func (h *hub) leaveLocked(c *Conn, room string) {
+	if members, ok := h.rooms[room]; ok {
+		delete(members, c.ID)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.rooms, room)
+	c.mu.Unlock()
+}
+
+func (h *hub) Broadcast(data []byte) {
+	h.broadcastLocal(data)
+	h.publish("", data)
+}
+
+func (h *hub) BroadcastRoom(room string, data []byte) {
+	h.broadcastRoomLocal(room, data)
+	h.publish(room, data)
+}
+
+func (h *hub) broadcastRoomLocal(room string, data []byte) {
+	h.mu.RLock()
+	targets := make([]*Conn, 0, len(h.rooms[room]))
+	for _, c := range h.rooms[room] {
+		targets = append(targets, c)
+	}
+	h.mu.RUnlock()
+
+	h.deliver(targets, data)
+}
+
+func (h *hub) broadcastLocal(data []byte) {
+	h.mu.RLock()
+	targets := make([]*Conn, 0, len(h.conns))
+	for _, c := range h.conns {
+		targets = append(targets, c)
+	}
+	h.mu.RUnlock()
+
+	h.deliver(targets, data)
+}
+
+func (h *hub) SetRelay(relay Relay) error {
+	if err := relay.Start(h.handleRelayMessage); err != nil {
+		log.Error().Str("FunctionName", "SetRelay").Str("ERRO_WS", "Erro ao iniciar o relay").Msg(err.Error())
+		return err
+	}
+
+	h.mu.Lock()
+	h.relay = relay
+	h.mu.Unlock()
+
+	return nil
+}
+
+func (h *hub) InstanceID() string {
+	return h.instanceID
+}
+
+func (h *hub) publish(room string, data []byte) {
+	h.mu.RLock()
+	relay := h.relay
+	h.mu.RUnlock()
+
+	if relay == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_WS_WRITE_WAIT)
+	defer cancel()
+
+	err := relay.Publish(ctx, &RelayMessage{
+		Kind:       RELAY_MESSAGE_KIND,
+		InstanceID: h.instanceID,
+		Room:       room,
+		Data:       data,
+	})
+	if err != nil {
+		log.Error().Str("FunctionName", "publish").Str("ERRO_WS", "Erro ao publicar no relay").Msg(err.Error())
+	}
+}
+
+// handleRelayMessage entrega localmente as mensagens vindas de outras
+// instâncias; as mensagens publicadas por esta instância são descartadas
+// para evitar entrega duplicada.
+func (h *hub) handleRelayMessage(msg *RelayMessage) {
+	if msg.InstanceID == h.instanceID {
+		return
+	}
+
+	if msg.Room == "" {
+		h.broadcastLocal(msg.Data)
+		return
+	}
+
+	h.broadcastRoomLocal(msg.Room, msg.Data)
+}
+
+// deliver envia para cada conexão sem bloquear; conexões com o buffer cheio
+// são consideradas lentas e encerradas para não segurar as demais.
+func (h *hub) deliver(targets []*Conn, data []byte) {
+	for _, c := range targets {
+		if err := c.Send(data); errors.Is(err, ErrSendBufferFull) {
+			log.Warn().Str("FunctionName", "Broadcast").Str("ConnID", c.ID).Msg("Slow consumer, closing connection")
+			c.CloseWithReason(gws.CloseTryAgainLater, "slow consumer")
+		}
+	}
+}
+
+func (h *hub) GetConn(id string) (*Conn, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	c, ok := h.conns[id]
+	return c, ok
+}
+
+func (h *hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.conns)
+}
+
+func (h *hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.rooms[room])
+}
+
+func (h *hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	relay := h.relay
+	h.relay = nil
+	targets := make([]*Conn, 0, len(h.conns))
+	for _, c := range h.conns {
+		targets = append(targets, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range targets {
+		c.CloseWithReason(gws.CloseGoingAway, "server shutdown")
+	}
+
+	if relay != nil {
+		if err := relay.Close(); err != nil {
+			log.Error().Str("FunctionName", "Shutdown").Str("ERRO_WS", "Erro ao encerrar o relay").Msg(err.Error())
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info().Str("FunctionName", "Shutdown").Msg("WebSocket hub closed successfully")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *hub) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_WS_WRITE_WAIT)
+	defer cancel()
+
+	if err := h.Shutdown(ctx); err != nil {
+		log.Error().Str("FunctionName", "Close").Str("ERRO_WS", "Erro ao encerrar as conexões").Msg(err.Error())
+	}
+}
+
+func (h *hub) unregister(c *Conn) {
+	h.mu.Lock()
+	if _, ok := h.conns[c.ID]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.conns, c.ID)
+
+	c.mu.Lock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	c.mu.Unlock()
+
+	for _, room := range rooms {
+		h.leaveLocked(c, room)
+	}
+	h.mu.Unlock()
+
+	if h.onDisconnect != nil {
+		h.onDisconnect(c)
+	}
+}
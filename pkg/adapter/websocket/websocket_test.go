@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faelp22/go-commons-libs/core/config"
+	gws "github.com/gorilla/websocket"
+)
+
+func newTestHub(t *testing.T) (*hub, *httptest.Server) {
+	t.Helper()
+
+	h := New(&config.Config{WSConfig: &config.WSConfig{WS_PING_INTERVAL: 1}}).(*hub)
+	// ?room=X coloca a conexão na sala X
+	h.OnConnect(func(c *Conn) {
+		if room := c.Request.URL.Query().Get("room"); room != "" {
+			h.Join(c, room)
+		}
+	})
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(func() {
+		h.Close()
+		srv.Close()
+	})
+
+	return h, srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, query string) *gws.Conn {
+	t.Helper()
+
+	ws, resp, err := gws.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/?"+query, nil)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("dial: %v (status %d)", err, status)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	return ws
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readText(t *testing.T, ws *gws.Conn) string {
+	t.Helper()
+
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNewConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		conf       config.WSConfig
+		wantBuffer int
+		wantPing   int
+		wantMax    int64
+	}{
+		{name: "empty", wantBuffer: DEFAULT_WS_SEND_BUFFER_SIZE, wantPing: DEFAULT_WS_PING_INTERVAL, wantMax: DEFAULT_WS_MAX_MESSAGE_SIZE},
+		{name: "config", conf: config.WSConfig{WS_SEND_BUFFER_SIZE: 8, WS_PING_INTERVAL: 5, WS_MAX_MESSAGE_SIZE: 10}, wantBuffer: 8, wantPing: 5, wantMax: 10},
+		{
+			name:       "env",
+			env:        map[string]string{"SRV_WS_SEND_BUFFER_SIZE": "16", "SRV_WS_PING_INTERVAL": "10", "SRV_WS_MAX_MESSAGE_SIZE": "100"},
+			wantBuffer: 16, wantPing: 10, wantMax: 100,
+		},
+		{
+			name:       "env zero",
+			env:        map[string]string{"SRV_WS_SEND_BUFFER_SIZE": "0", "SRV_WS_PING_INTERVAL": "0", "SRV_WS_MAX_MESSAGE_SIZE": "0"},
+			wantBuffer: DEFAULT_WS_SEND_BUFFER_SIZE, wantPing: DEFAULT_WS_PING_INTERVAL, wantMax: DEFAULT_WS_MAX_MESSAGE_SIZE,
+		},
+		{
+			name:       "env negative",
+			env:        map[string]string{"SRV_WS_SEND_BUFFER_SIZE": "-1", "SRV_WS_PING_INTERVAL": "-5", "SRV_WS_MAX_MESSAGE_SIZE": "-1"},
+			wantBuffer: DEFAULT_WS_SEND_BUFFER_SIZE, wantPing: DEFAULT_WS_PING_INTERVAL, wantMax: DEFAULT_WS_MAX_MESSAGE_SIZE,
+		},
+		{
+			name:       "env invalid",
+			env:        map[string]string{"SRV_WS_SEND_BUFFER_SIZE": "x", "SRV_WS_PING_INTERVAL": "x", "SRV_WS_MAX_MESSAGE_SIZE": "x"},
+			wantBuffer: DEFAULT_WS_SEND_BUFFER_SIZE, wantPing: DEFAULT_WS_PING_INTERVAL, wantMax: DEFAULT_WS_MAX_MESSAGE_SIZE,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			wsConf := tt.conf
+			h := New(&config.Config{WSConfig: &wsConf}).(*hub)
+
+			if h.conf.WS_SEND_BUFFER_SIZE != tt.wantBuffer {
+				t.Errorf("buffer = %d, want %d", h.conf.WS_SEND_BUFFER_SIZE, tt.wantBuffer)
+			}
+			if h.conf.WS_PING_INTERVAL != tt.wantPing {
+				t.Errorf("ping = %d, want %d", h.conf.WS_PING_INTERVAL, tt.wantPing)
+			}
+			if h.conf.WS_MAX_MESSAGE_SIZE != tt.wantMax {
+				t.Errorf("max = %d, want %d", h.conf.WS_MAX_MESSAGE_SIZE, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		host    string
+		want    bool
+	}{
+		{name: "no origin", want: true},
+		{name: "same origin", origin: "https://api.x.com", host: "api.x.com", want: true},
+		{name: "cross origin without list", origin: "https://evil.com", host: "api.x.com"},
+		{name: "allowed", allowed: []string{"https://app.x.com"}, origin: "https://APP.x.com", want: true},
+		{name: "not allowed", allowed: []string{"https://app.x.com"}, origin: "https://evil.com"},
+		{name: "wildcard", allowed: []string{"*"}, origin: "https://any.com", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &hub{conf: &config.WSConfig{WS_ALLOWED_ORIGINS: tt.allowed}}
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := h.checkOrigin(r); got != tt.want {
+				t.Errorf("checkOrigin = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHubRooms(t *testing.T) {
+	h, srv := newTestHub(t)
+
+	h.OnMessage(func(c *Conn, data []byte) {
+		c.Send(append([]byte("echo:"), data...))
+	})
+
+	member := dial(t, srv, "room=chat")
+	other := dial(t, srv, "")
+	waitFor(t, func() bool { return h.RoomCount("chat") == 1 && h.Count() == 2 })
+
+	var joined *Conn
+	h.mu.RLock()
+	for _, c := range h.rooms["chat"] {
+		joined = c
+	}
+	h.mu.RUnlock()
+	if got, ok := h.GetConn(joined.ID); !ok || got != joined {
+		t.Fatal("GetConn failed")
+	}
+	if rooms := joined.Rooms(); len(rooms) != 1 || rooms[0] != "chat" {
+		t.Fatalf("Rooms = %v", rooms)
+	}
+
+	h.Broadcast([]byte("all"))
+	if got := readText(t, member); got != "all" {
+		t.Errorf("member = %q", got)
+	}
+	if got := readText(t, other); got != "all" {
+		t.Errorf("other = %q", got)
+	}
+
+	h.BroadcastRoom("chat", []byte("room"))
+	if got := readText(t, member); got != "room" {
+		t.Errorf("member = %q", got)
+	}
+
+	other.WriteMessage(gws.TextMessage, []byte("ping"))
+	if got := readText(t, other); got != "echo:ping" {
+		t.Errorf("other = %q, want only the echo (no room message)", got)
+	}
+
+	h.Leave(joined, "chat")
+	if h.RoomCount("chat") != 0 {
+		t.Fatalf("RoomCount after leave = %d", h.RoomCount("chat"))
+	}
+
+	member.Close()
+	waitFor(t, func() bool { return h.Count() == 1 })
+}
+
+func TestHubSlowConsumer(t *testing.T) {
+	h := New(&config.Config{WSConfig: &config.WSConfig{WS_SEND_BUFFER_SIZE: 1, WS_PING_INTERVAL: 1}}).(*hub)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	defer h.Close()
+
+	disconnected := make(chan struct{})
+	h.OnDisconnect(func(c *Conn) { close(disconnected) })
+
+	dial(t, srv, "")
+	waitFor(t, func() bool { return h.Count() == 1 })
+
+	// O cliente não lê; o buffer de 1 mensagem enche e a conexão é encerrada
+	for i := 0; i < 1000 && h.Count() > 0; i++ {
+		h.Broadcast([]byte(strings.Repeat("x", 1024)))
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow consumer was not disconnected")
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	h := New(&config.Config{WSConfig: &config.WSConfig{WS_PING_INTERVAL: 1}}).(*hub)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ws := dial(t, srv, "")
+	waitFor(t, func() bool { return h.Count() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := ws.ReadMessage()
+	if !gws.IsCloseError(err, gws.CloseGoingAway) {
+		t.Fatalf("err = %v, want CloseGoingAway", err)
+	}
+
+	// Novas conexões são recusadas durante o shutdown
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", resp.StatusCode)
+	}
+}
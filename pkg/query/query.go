@@ -0,0 +1,163 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Operadores de filtro aceitos na query string (ex: ?age[gte]=18)
+const (
+	OP_EQ   = "eq"
+	OP_NE   = "ne"
+	OP_GT   = "gt"
+	OP_GTE  = "gte"
+	OP_LT   = "lt"
+	OP_LTE  = "lte"
+	OP_IN   = "in"
+	OP_LIKE = "like"
+)
+
+// Tipos usados para converter os valores recebidos como texto
+const (
+	TYPE_STRING = "string"
+	TYPE_INT    = "int"
+	TYPE_FLOAT  = "float"
+	TYPE_BOOL   = "bool"
+	TYPE_TIME   = "time" // RFC 3339
+)
+
+// Field campo permitido para ordenação e filtro
+type Field struct {
+	// Column nome da coluna no SQL ou do campo no Mongo (padrão: o próprio nome do campo)
+	Column string
+	// Type tipo do valor (padrão: TYPE_STRING)
+	Type string
+	// Sortable permite usar o campo no sort
+	Sortable bool
+	// Filterable permite usar o campo nos filtros
+	Filterable bool
+	// Operators operadores aceitos (padrão: todos; like apenas em TYPE_STRING)
+	Operators []string
+}
+
+// Sort ordenação por um campo
+type Sort struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// Filter condição aplicada em um campo. Value já está convertido para o Type
+// do campo; no operador in os valores ficam em Values.
+type Filter struct {
+	Field  string
+	Column string
+	Op     string
+	Value  interface{}
+	Values []interface{}
+}
+
+// Query representação neutra de paginação, ordenação e filtros, usada pelos
+// tradutores de SQL (pgsql) e bson (mongodb).
+type Query struct {
+	// Page página atual começando em 1 (paginação por offset)
+	Page  int
+	Limit int
+	Sort  []Sort
+	// Filters são combinados com AND
+	Filters []Filter
+	// Cursor valor recebido no ?cursor= ou no ?before= (paginação por cursor)
+	Cursor string
+	// After valores do Sort do item de referência, decodificados do Cursor
+	After []interface{}
+	// Before indica que o Cursor veio do ?before=: a página é a anterior ao item
+	// de referência. Os tradutores invertem a ordenação para buscar os itens
+	// mais próximos dele, então o resultado precisa ser revertido (slices.Reverse)
+	// antes de ser devolvido.
+	Before bool
+}
+
+// Offset quantidade de itens pulados na paginação por offset
+func (q *Query) Offset() int {
+	if q.Page <= 1 || q.Cursor != "" {
+		return 0
+	}
+	return (q.Page - 1) * q.Limit
+}
+
+// IsCursor indica que a paginação é por cursor
+func (q *Query) IsCursor() bool {
+	return len(q.After) > 0
+}
+
+// SortDesc direção usada na consulta para o campo i do Sort, já invertida
+// quando Before é verdadeiro
+func (q *Query) SortDesc(i int) bool {
+	return q.Sort[i].Desc != q.Before
+}
+
+// EncodeCursor gera o cursor a partir dos valores dos campos do Sort, na mesma
+// ordem do Sort: do último item retornado para a próxima página e do primeiro
+// para a anterior.
+//
+// Exemplo de Uso:
+//
+//	last := users[len(users)-1]
+//	next := query.EncodeCursor(last.CreatedAt, last.ID)
+//	first := users[0]
+//	prev := query.EncodeCursor(first.CreatedAt, first.ID)
+func EncodeCursor(values ...interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodifica o cursor convertendo cada valor para o tipo do campo do Sort
+func DecodeCursor(cursor string, types []string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	// UseNumber evita perder a precisão de IDs int64
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw []interface{}
+	if err := dec.Decode(&raw); err != nil || len(raw) != len(types) {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, v := range raw {
+		converted, err := ParseValue(fmt.Sprint(v), types[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		values[i] = converted
+	}
+
+	return values, nil
+}
+
+// ParseValue converte o texto recebido para o tipo informado
+func ParseValue(value, fieldType string) (interface{}, error) {
+	switch fieldType {
+	case TYPE_INT:
+		return strconv.ParseInt(value, 10, 64)
+	case TYPE_FLOAT:
+		return strconv.ParseFloat(value, 64)
+	case TYPE_BOOL:
+		return strconv.ParseBool(value)
+	case TYPE_TIME:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
+}
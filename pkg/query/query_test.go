@@ -0,0 +1,108 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseValue(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		fieldType string
+		want      interface{}
+		wantErr   bool
+	}{
+		{name: "string", value: "ana", fieldType: TYPE_STRING, want: "ana"},
+		{name: "empty type is string", value: "ana", want: "ana"},
+		{name: "int", value: "9007199254740993", fieldType: TYPE_INT, want: int64(9007199254740993)},
+		{name: "invalid int", value: "1.5", fieldType: TYPE_INT, wantErr: true},
+		{name: "float", value: "1.5", fieldType: TYPE_FLOAT, want: 1.5},
+		{name: "bool", value: "true", fieldType: TYPE_BOOL, want: true},
+		{name: "invalid bool", value: "yes", fieldType: TYPE_BOOL, wantErr: true},
+		{name: "time", value: "2026-01-02T03:04:05Z", fieldType: TYPE_TIME, want: ts},
+		{name: "invalid time", value: "2026-01-02", fieldType: TYPE_TIME, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseValue(tt.value, tt.fieldType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotTime, ok := got.(time.Time); ok {
+				if !gotTime.Equal(tt.want.(time.Time)) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+	cursor := EncodeCursor(ts, int64(9007199254740993), "ana")
+	values, err := DecodeCursor(cursor, []string{TYPE_TIME, TYPE_INT, TYPE_STRING})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !values[0].(time.Time).Equal(ts) {
+		t.Errorf("time = %v, want %v", values[0], ts)
+	}
+	if values[1] != int64(9007199254740993) {
+		t.Errorf("id = %v, precision lost", values[1])
+	}
+	if values[2] != "ana" {
+		t.Errorf("name = %v", values[2])
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+		types  []string
+	}{
+		{name: "not base64", cursor: "!!!", types: []string{TYPE_INT}},
+		{name: "not json", cursor: "bm90LWpzb24", types: []string{TYPE_INT}},
+		{name: "wrong length", cursor: EncodeCursor(1, 2), types: []string{TYPE_INT}},
+		{name: "wrong type", cursor: EncodeCursor("x"), types: []string{TYPE_INT}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor, tt.types); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		q    Query
+		want int
+	}{
+		{q: Query{Page: 1, Limit: 20}, want: 0},
+		{q: Query{Page: 3, Limit: 20}, want: 40},
+		{q: Query{Page: 3, Limit: 20, Cursor: "x"}, want: 0},
+		{q: Query{Page: 0, Limit: 20}, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.Offset(); got != tt.want {
+			t.Errorf("Offset(%+v) = %d, want %d", tt.q, got, tt.want)
+		}
+	}
+}
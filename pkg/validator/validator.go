@@ -0,0 +1,306 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TAG_NAME nome da tag usada nas structs
+//
+// Regras disponíveis (separadas por vírgula):
+//
+//	required        o campo não pode ter o valor zero (string vazia, nil, slice vazio...)
+//	min=N / max=N   tamanho mínimo/máximo para string, slice e map ou valor para números
+//	enum=a|b|c      o valor precisa ser um dos itens informados
+//	regex=PADRAO    a string precisa casar com o padrão; deve ser a última regra da tag
+//
+// Structs aninhadas, ponteiros para struct e slices de struct são validados
+// recursivamente.
+//
+// IMPORTANTE: as regras são aplicadas também ao valor zero. Um int com
+// `validate:"min=18"` rejeita 0 e uma string com `validate:"enum=a|b"` rejeita "".
+// Para campos opcionais use ponteiro: um ponteiro nil só falha no required e
+// ignora as demais regras.
+//
+// Exemplo:
+//
+//	type User struct {
+//	    Name  string   `json:"name" validate:"required,min=3,max=80"`
+//	    Role  *string  `json:"role" validate:"enum=admin|user"`
+//	    Email string   `json:"email" validate:"required,regex=^[^@]+@[^@]+$"`
+//	    Tags  []string `json:"tags" validate:"max=5"`
+//	}
+const TAG_NAME = "validate"
+
+// FieldError descreve uma regra violada em um campo
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Msg   string `json:"msg"`
+}
+
+// ValidationErrors lista de todos os campos inválidos encontrados
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Field, fe.Msg))
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+var regexCache sync.Map
+
+// Validate valida v (struct ou ponteiro para struct) a partir das tags `validate`.
+//
+// Retorna ValidationErrors com todos os campos inválidos, nil se tudo estiver
+// correto, ou um erro comum se alguma tag estiver mal definida.
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	var errs ValidationErrors
+	if err := validateValue(rv, "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateValue(rv reflect.Value, path string, errs *ValidationErrors) error {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return validateValue(rv.Elem(), path, errs)
+	case reflect.Struct:
+		return validateStruct(rv, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := validateValue(rv.Index(i), fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateStruct(rv reflect.Value, path string, errs *ValidationErrors) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		fieldPath := joinPath(path, fieldName(sf))
+
+		// Campos embutidos mantêm o path do pai, assim como no encoding/json
+		if sf.Anonymous && sf.Tag.Get("json") == "" {
+			fieldPath = path
+		}
+
+		if tag := sf.Tag.Get(TAG_NAME); tag != "" && tag != "-" {
+			if err := applyRules(fv, fieldPath, tag, errs); err != nil {
+				return fmt.Errorf("field %s.%s: %w", rt.Name(), sf.Name, err)
+			}
+		}
+
+		if sf.Tag.Get(TAG_NAME) == "-" {
+			continue
+		}
+
+		if err := validateValue(fv, fieldPath, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyRules(fv reflect.Value, path, tag string, errs *ValidationErrors) error {
+	rules := splitRules(tag)
+
+	required := false
+	for _, rule := range rules {
+		if rule == "required" {
+			required = true
+		}
+	}
+
+	if fv.IsZero() && required {
+		*errs = append(*errs, FieldError{Field: path, Rule: "required", Msg: "is required"})
+		return nil
+	}
+
+	// Ponteiros são validados pelo valor apontado; nil é tratado como ausente
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		var fe *FieldError
+		var err error
+
+		switch name {
+		case "required":
+			continue
+		case "min":
+			fe, err = checkBound(fv, param, true)
+		case "max":
+			fe, err = checkBound(fv, param, false)
+		case "enum":
+			fe = checkEnum(fv, param)
+		case "regex":
+			fe, err = checkRegex(fv, param)
+		default:
+			err = fmt.Errorf("unknown validation rule %q", name)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if fe != nil {
+			fe.Field = path
+			fe.Rule = name
+			*errs = append(*errs, *fe)
+		}
+	}
+
+	return nil
+}
+
+// SplitRules separa as regras de uma tag validate, por exemplo para gerar
+// documentação a partir das mesmas tags usadas na validação
+func SplitRules(tag string) []string {
+	return splitRules(tag)
+}
+
+// splitRules separa as regras por vírgula mantendo o padrão do regex intacto
+func splitRules(tag string) []string {
+	if idx := strings.Index(tag, "regex="); idx >= 0 {
+		head := strings.TrimSuffix(tag[:idx], ",")
+		rules := []string{}
+		if head != "" {
+			rules = strings.Split(head, ",")
+		}
+		return append(rules, tag[idx:])
+	}
+
+	return strings.Split(tag, ",")
+}
+
+func checkBound(fv reflect.Value, param string, isMin bool) (*FieldError, error) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min/max value %q", param)
+	}
+
+	var value float64
+	unit := ""
+
+	switch fv.Kind() {
+	case reflect.String:
+		value = float64(len([]rune(fv.String())))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value = float64(fv.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = fv.Float()
+	default:
+		return nil, fmt.Errorf("min/max not supported for kind %s", fv.Kind())
+	}
+
+	if isMin && value < limit {
+		return &FieldError{Msg: fmt.Sprintf("must be at least %s%s", param, unit)}, nil
+	}
+
+	if !isMin && value > limit {
+		return &FieldError{Msg: fmt.Sprintf("must be at most %s%s", param, unit)}, nil
+	}
+
+	return nil, nil
+}
+
+func checkEnum(fv reflect.Value, param string) *FieldError {
+	options := strings.Split(param, "|")
+	value := fmt.Sprintf("%v", fv.Interface())
+
+	for _, opt := range options {
+		if value == opt {
+			return nil
+		}
+	}
+
+	return &FieldError{Msg: fmt.Sprintf("must be one of [%s]", strings.Join(options, ", "))}
+}
+
+func checkRegex(fv reflect.Value, pattern string) (*FieldError, error) {
+	if fv.Kind() != reflect.String {
+		return nil, fmt.Errorf("regex not supported for kind %s", fv.Kind())
+	}
+
+	var re *regexp.Regexp
+	if cached, ok := regexCache.Load(pattern); ok {
+		re = cached.(*regexp.Regexp)
+	} else {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		regexCache.Store(pattern, compiled)
+		re = compiled
+	}
+
+	if !re.MatchString(fv.String()) {
+		return &FieldError{Msg: fmt.Sprintf("must match pattern %s", pattern)}, nil
+	}
+
+	return nil, nil
+}
+
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
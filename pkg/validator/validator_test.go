@@ -0,0 +1,192 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+type validatorAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatorUser struct {
+	Name    string             `json:"name" validate:"required,min=3,max=10"`
+	Age     int                `json:"age" validate:"min=18,max=130"`
+	Role    *string            `json:"role" validate:"enum=admin|user"`
+	Email   string             `json:"email" validate:"regex=^[^@]+@[^@]+$"`
+	Tags    []string           `json:"tags" validate:"max=2"`
+	Address validatorAddress   `json:"address"`
+	Others  []validatorAddress `json:"others"`
+	Skip    validatorAddress   `json:"skip" validate:"-"`
+}
+
+func validUser() validatorUser {
+	role := "admin"
+	return validatorUser{
+		Name:    "ana",
+		Age:     30,
+		Role:    &role,
+		Email:   "ana@x.com",
+		Address: validatorAddress{City: "Recife"},
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(u *validatorUser)
+		want   []FieldError
+	}{
+		{name: "valid", mutate: func(u *validatorUser) {}},
+		{
+			name:   "required",
+			mutate: func(u *validatorUser) { u.Name = "" },
+			want:   []FieldError{{Field: "name", Rule: "required"}},
+		},
+		{
+			name:   "min string",
+			mutate: func(u *validatorUser) { u.Name = "an" },
+			want:   []FieldError{{Field: "name", Rule: "min"}},
+		},
+		{
+			name:   "max string counts runes",
+			mutate: func(u *validatorUser) { u.Name = "ááááááááááá" },
+			want:   []FieldError{{Field: "name", Rule: "max"}},
+		},
+		{
+			name:   "min applies to zero value",
+			mutate: func(u *validatorUser) { u.Age = 0 },
+			want:   []FieldError{{Field: "age", Rule: "min"}},
+		},
+		{
+			name:   "max number",
+			mutate: func(u *validatorUser) { u.Age = 200 },
+			want:   []FieldError{{Field: "age", Rule: "max"}},
+		},
+		{
+			name:   "enum",
+			mutate: func(u *validatorUser) { u.Role = strPtr("root") },
+			want:   []FieldError{{Field: "role", Rule: "enum"}},
+		},
+		{
+			name:   "enum applies to empty pointer value",
+			mutate: func(u *validatorUser) { u.Role = strPtr("") },
+			want:   []FieldError{{Field: "role", Rule: "enum"}},
+		},
+		{name: "nil pointer is optional", mutate: func(u *validatorUser) { u.Role = nil }},
+		{
+			name:   "regex",
+			mutate: func(u *validatorUser) { u.Email = "invalid" },
+			want:   []FieldError{{Field: "email", Rule: "regex"}},
+		},
+		{
+			name:   "slice max",
+			mutate: func(u *validatorUser) { u.Tags = []string{"a", "b", "c"} },
+			want:   []FieldError{{Field: "tags", Rule: "max"}},
+		},
+		{
+			name:   "nested struct",
+			mutate: func(u *validatorUser) { u.Address.City = "" },
+			want:   []FieldError{{Field: "address.city", Rule: "required"}},
+		},
+		{
+			name:   "slice of structs",
+			mutate: func(u *validatorUser) { u.Others = []validatorAddress{{City: "x"}, {}} },
+			want:   []FieldError{{Field: "others[1].city", Rule: "required"}},
+		},
+		{name: "skip tag", mutate: func(u *validatorUser) { u.Skip = validatorAddress{} }},
+		{
+			name: "collects every field",
+			mutate: func(u *validatorUser) {
+				u.Name = ""
+				u.Age = 10
+				u.Email = "x"
+			},
+			want: []FieldError{
+				{Field: "name", Rule: "required"},
+				{Field: "age", Rule: "min"},
+				{Field: "email", Rule: "regex"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := validUser()
+			tt.mutate(&u)
+
+			err := Validate(&u)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("error = %v, want ValidationErrors", err)
+			}
+			if len(verrs) != len(tt.want) {
+				t.Fatalf("errors = %v, want %v", verrs, tt.want)
+			}
+			for i, fe := range verrs {
+				if fe.Field != tt.want[i].Field || fe.Rule != tt.want[i].Rule {
+					t.Errorf("errors[%d] = %s/%s, want %s/%s", i, fe.Field, fe.Rule, tt.want[i].Field, tt.want[i].Rule)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "unknown rule", v: &struct {
+			A string `validate:"foo"`
+		}{A: "x"}},
+		{name: "invalid bound", v: &struct {
+			A int `validate:"min=abc"`
+		}{A: 1}},
+		{name: "invalid regex", v: &struct {
+			A string `validate:"regex=["`
+		}{A: "x"}},
+		{name: "regex on int", v: &struct {
+			A int `validate:"regex=^1$"`
+		}{A: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.v)
+			var verrs ValidationErrors
+			if err == nil || errors.As(err, &verrs) {
+				t.Fatalf("error = %v, want tag definition error", err)
+			}
+		})
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	var u *validatorUser
+	if err := Validate(u); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitRules(t *testing.T) {
+	got := SplitRules("required,min=1,regex=^a,b$")
+	want := []string{"required", "min=1", "regex=^a,b$"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -7,59 +7,50 @@ import (
 
 	"github.com/faelp22/go-commons-libs/core/config"
 	"github.com/faelp22/go-commons-libs/pkg/adapter/httpserver"
+	"github.com/faelp22/go-commons-libs/pkg/adapter/websocket"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
-
-func handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	log.Println("WebSocket CheckOrigin")
+func main() {
+	os.Setenv("SRV_HTTP_PORT", "8080")
+	os.Setenv("SRV_WS_ALLOWED_ORIGINS", "http://localhost:8080,http://127.0.0.1:8080")
 
-	ws, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("ERROR: %v", err)
-		return
-	}
-	defer ws.Close()
+	conf := config.NewDefaultConf()
+	conf.HttpConfig = &config.HttpConfig{}
+	router := mux.NewRouter()
 
-	log.Println("New terminal connection")
+	hub := websocket.New(conf)
 
-	// Echo server simples
-	for {
-		messageType, message, err := ws.ReadMessage()
-		if err != nil {
-			log.Printf("Read error: %v", err)
-			break
+	hub.OnConnect(func(c *websocket.Conn) {
+		log.Printf("New connection %s", c.ID)
+		// A sala pode vir da query, ex: ws://localhost:8080/ws?room=terminal
+		room := c.Request.URL.Query().Get("room")
+		if room == "" {
+			room = "lobby"
 		}
+		hub.Join(c, room)
+	})
 
-		log.Printf("Received: %s", message)
-
-		err = ws.WriteMessage(messageType, message)
-		if err != nil {
-			log.Printf("Write error: %v", err)
-			break
+	// Repassa a mensagem para todas as conexões das salas do remetente
+	hub.OnMessage(func(c *websocket.Conn, data []byte) {
+		log.Printf("Received from %s: %s", c.ID, data)
+		for _, room := range c.Rooms() {
+			hub.BroadcastRoom(room, data)
 		}
-	}
-}
-
-func main() {
-	os.Setenv("SRV_HTTP_PORT", "8080")
+	})
 
-	conf := config.NewDefaultConf()
-	router := mux.NewRouter()
+	hub.OnDisconnect(func(c *websocket.Conn) {
+		log.Printf("Connection %s closed", c.ID)
+	})
 
-	router.HandleFunc("/ws", handleWebSocket)
+	router.Handle("/ws", hub)
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
 	srv := httpserver.New(router, conf, nil)
+	srv.RegisterOnShutdown(hub.Close)
 
 	log.Printf("Server starting on port %s", conf.PORT)
 	log.Fatal(srv.ListenAndServe())